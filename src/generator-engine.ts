@@ -183,7 +183,17 @@ export class GeneratorEngine {
         });
       }
 
-      // Go dependencies are handled by Wails build
+      // Resolve the Go modules feature templates import and record them in go.sum
+      if (existsSync(join(config.projectPath, 'go.mod'))) {
+        spinner.text = 'Installing Go dependencies...';
+        try {
+          await execa('go', ['mod', 'tidy'], { cwd: config.projectPath });
+        } catch {
+          spinner.warn('go mod tidy failed; run it in the project before building');
+          return;
+        }
+      }
+
       spinner.succeed('Dependencies installed');
     } catch (error) {
       spinner.fail('Failed to install dependencies');
@@ -3,7 +3,7 @@ import { join } from 'path';
 import type { GeneratorConfig } from '../types.js';
 import ora from 'ora';
 import { readTemplate } from './template-reader.js';
-import { patchMainGo, mainGoContains } from './helpers.js';
+import { patchMainGo, mainGoContains, patchAppLifecycle, patchMacOptions, addGoRequires } from './helpers.js';
 
 export async function applySingleInstance(config: GeneratorConfig): Promise<void> {
   const spinner = ora('Adding single instance lock...').start();
@@ -15,6 +15,29 @@ export async function applySingleInstance(config: GeneratorConfig): Promise<void
     
     await fse.writeFile(singleInstancePath, singleInstanceCode);
 
+    // Platform-specific file locking (flock / LockFileEx)
+    for (const platformFile of ['singleinstance_unix.go', 'singleinstance_windows.go']) {
+      const platformCode = await readTemplate(`app-features/${platformFile}`, config.wailsVersion);
+      await fse.writeFile(join(config.projectPath, platformFile), platformCode);
+    }
+
+    if (config.features.testingBackend) {
+      for (const testFile of ['singleinstance_test.go', 'singleinstance_unix_test.go']) {
+        const testGoCode = await readTemplate(`app-features/${testFile}`, config.wailsVersion);
+        await fse.writeFile(join(config.projectPath, testFile), testGoCode);
+      }
+    }
+
+    // Create frontend helper
+    const frontendExampleDir = join(config.projectPath, 'frontend-examples');
+    await fse.ensureDir(frontendExampleDir);
+
+    const ext = config.features.typescript ? 'ts' : 'js';
+    const singleInstanceHelperPath = join(frontendExampleDir, `singleinstance-helper.${ext}`);
+    const singleInstanceHelperCode = await readTemplate(`app-features/singleinstance-helper.${ext}`, config.wailsVersion);
+
+    await fse.writeFile(singleInstanceHelperPath, singleInstanceHelperCode);
+
     // Patch main.go to initialize single instance lock
     const alreadyPatched = await mainGoContains(config.projectPath, 'initSingleInstance');
     
@@ -80,6 +103,13 @@ export async function applyAutoUpdate(config: GeneratorConfig): Promise<void> {
 
     await fse.writeFile(updateGoPath, updateGoCode);
 
+    // v3 exposes update checks as a service rather than App methods
+    if (config.wailsVersion === 3 && !(await mainGoContains(config.projectPath, 'UpdateService'))) {
+      await patchMainGo(config.projectPath, 3, {
+        addService: '&UpdateService{}',
+      });
+    }
+
     // Create frontend helper
     const frontendExampleDir = join(config.projectPath, 'frontend-examples');
     await fse.ensureDir(frontendExampleDir);
@@ -101,10 +131,26 @@ export async function applyNativeDialogs(config: GeneratorConfig): Promise<void>
   const spinner = ora('Adding native dialogs...').start();
   
   try {
-    const dialogsPath = join(config.projectPath, 'dialogs.go');
-    const dialogsCode = await readTemplate('app-features/dialogs.go', config.wailsVersion);
+    // Bindings plus the option types, remembered state and message dialogs shared by v2 and v3
+    for (const dialogsFile of ['dialogs.go', 'dialogs_options.go', 'dialogs_state.go', 'dialogs_message.go', 'dialogs_message_windows.go', 'dialogs_message_other.go']) {
+      const dialogsCode = (await readTemplate(`app-features/${dialogsFile}`, config.wailsVersion))
+        .replace(/{{PROJECT_NAME}}/g, config.projectName);
+      await fse.writeFile(join(config.projectPath, dialogsFile), dialogsCode);
+    }
 
-    await fse.writeFile(dialogsPath, dialogsCode);
+    if (config.features.testingBackend) {
+      for (const testFile of ['dialogs_options_test.go', 'dialogs_state_test.go', 'dialogs_message_test.go']) {
+        const testGoCode = await readTemplate(`app-features/${testFile}`, config.wailsVersion);
+        await fse.writeFile(join(config.projectPath, testFile), testGoCode);
+      }
+    }
+
+    // v3 exposes the dialogs as a service rather than App methods
+    if (config.wailsVersion === 3 && !(await mainGoContains(config.projectPath, 'DialogService'))) {
+      await patchMainGo(config.projectPath, 3, {
+        addService: '&DialogService{}',
+      });
+    }
 
     // Create example frontend code
     const frontendExampleDir = join(config.projectPath, 'frontend-examples');
@@ -133,6 +179,13 @@ export async function applyAppConfig(config: GeneratorConfig): Promise<void> {
 
     await fse.writeFile(configGoPath, configGoCode);
 
+    // v3 exposes the settings store as a service rather than App methods
+    if (config.wailsVersion === 3 && !(await mainGoContains(config.projectPath, 'ConfigService'))) {
+      await patchMainGo(config.projectPath, 3, {
+        addService: '&ConfigService{}',
+      });
+    }
+
     // Create frontend helper
     const frontendExampleDir = join(config.projectPath, 'frontend-examples');
     await fse.ensureDir(frontendExampleDir);
@@ -156,10 +209,62 @@ export async function applyDeepLinking(config: GeneratorConfig): Promise<void> {
   try {
     const deeplinkGoPath = join(config.projectPath, 'deeplink.go');
     const deeplinkGoCode = (await readTemplate('app-features/deeplink.go', config.wailsVersion))
-      .replace(/{{PROJECT_NAME_LOWER}}/g, config.projectName.toLowerCase());
+      .replace(/{{PROJECT_NAME_LOWER}}/g, config.projectName.toLowerCase())
+      .replace(/{{PROJECT_NAME}}/g, config.projectName);
 
     await fse.writeFile(deeplinkGoPath, deeplinkGoCode);
 
+    if (config.features.testingBackend) {
+      const testGoCode = await readTemplate('app-features/deeplink_test.go', config.wailsVersion);
+      await fse.writeFile(join(config.projectPath, 'deeplink_test.go'), testGoCode);
+    }
+
+    // v3 exposes deep link routing as a service rather than App methods
+    if (config.wailsVersion === 3 && !(await mainGoContains(config.projectPath, 'DeepLinkService'))) {
+      await patchMainGo(config.projectPath, 3, {
+        addService: '&DeepLinkService{}',
+      });
+    }
+
+    // macOS delivers links and opened documents as Apple events, not arguments
+    if (config.wailsVersion === 2) {
+      await patchMacOptions(config.projectPath, {
+        OnUrlOpen: 'app.onMacURLOpen',
+        OnFileOpen: 'app.onMacFileOpen',
+      });
+    }
+
+    // Installer fragments registering the URL scheme and document type
+    const fragments = [
+      { template: 'fileassoc.plist', target: join(config.projectPath, 'build', 'darwin', 'fileassoc.plist') },
+      { template: 'fileassoc.nsh', target: join(config.projectPath, 'build', 'windows', 'fileassoc.nsh') },
+    ];
+
+    for (const fragment of fragments) {
+      const fragmentCode = (await readTemplate(`app-features/${fragment.template}`, config.wailsVersion))
+        .replace(/{{PROJECT_NAME_LOWER}}/g, config.projectName.toLowerCase())
+        .replace(/{{PROJECT_NAME}}/g, config.projectName);
+      await fse.ensureDir(join(fragment.target, '..'));
+      await fse.writeFile(fragment.target, fragmentCode);
+    }
+
+    // Route deep links forwarded by a second instance
+    if (config.features.singleInstance) {
+      const forwardGoPath = join(config.projectPath, 'deeplink_forward.go');
+      const forwardGoCode = await readTemplate('app-features/deeplink-forward.go', config.wailsVersion);
+      await fse.writeFile(forwardGoPath, forwardGoCode);
+    }
+
+    // Create frontend helper
+    const frontendExampleDir = join(config.projectPath, 'frontend-examples');
+    await fse.ensureDir(frontendExampleDir);
+
+    const ext = config.features.typescript ? 'ts' : 'js';
+    const deeplinkHelperPath = join(frontendExampleDir, `deeplink-helper.${ext}`);
+    const deeplinkHelperCode = await readTemplate(`app-features/deeplink-helper.${ext}`, config.wailsVersion);
+
+    await fse.writeFile(deeplinkHelperPath, deeplinkHelperCode);
+
     spinner.succeed('Deep linking support added ');
   } catch (error) {
     spinner.fail('Failed to add deep linking');
@@ -171,11 +276,66 @@ export async function applyStartup(config: GeneratorConfig): Promise<void> {
   const spinner = ora('Adding startup/auto-launch support...').start();
   
   try {
-    const startupGoPath = join(config.projectPath, 'startup.go');
-    const startupGoCode = (await readTemplate('app-features/startup.go', config.wailsVersion))
-      .replace(/{{PROJECT_NAME}}/g, config.projectName);
+    // Shared bindings plus one build-tagged Autostarter per platform
+    const startupFiles = ['startup.go', 'startup_windows.go', 'startup_darwin.go', 'startup_linux.go', 'startup_other.go'];
+
+    for (const startupFile of startupFiles) {
+      const startupGoCode = (await readTemplate(`app-features/${startupFile}`, config.wailsVersion))
+        .replace(/{{PROJECT_NAME}}/g, config.projectName);
+      await fse.writeFile(join(config.projectPath, startupFile), startupGoCode);
+    }
+
+    // v3 exposes the startup bindings as a service rather than App methods
+    if (config.wailsVersion === 3 && !(await mainGoContains(config.projectPath, 'StartupService'))) {
+      await patchMainGo(config.projectPath, 3, {
+        addService: '&StartupService{}',
+      });
+    }
+
+    // Golden-file tests for the Linux desktop entry and systemd unit
+    if (config.features.testingBackend) {
+      const testGoCode = (await readTemplate('app-features/startup_linux_test.go', config.wailsVersion))
+        .replace(/{{PROJECT_NAME}}/g, config.projectName);
+      await fse.writeFile(join(config.projectPath, 'startup_linux_test.go'), testGoCode);
+
+      const goldenDir = join(config.projectPath, 'testdata', 'startup');
+      await fse.ensureDir(goldenDir);
+
+      const goldens = [
+        'default.desktop',
+        'args_and_spaces.desktop',
+        'delay.desktop',
+        'hidden.desktop',
+        'special_chars.desktop',
+        'systemd_default.service',
+        'systemd_target_delay.service',
+      ];
+      for (const golden of goldens) {
+        const goldenCode = (await readTemplate(`app-features/testdata/startup/${golden}`, config.wailsVersion))
+          .replace(/{{PROJECT_NAME}}/g, config.projectName)
+          .replace(/{{PROJECT_NAME_LOWER}}/g, config.projectName.toLowerCase());
+        await fse.writeFile(join(goldenDir, golden), goldenCode);
+      }
+    }
 
-    await fse.writeFile(startupGoPath, startupGoCode);
+    // Start hidden when launched at login, but only if the tray can bring the window back
+    if (config.features.systemTray && !(await mainGoContains(config.projectPath, 'StartHiddenAtLogin()'))) {
+      const mainGoPath = join(config.projectPath, 'main.go');
+      let content = await fse.readFile(mainGoPath, 'utf-8');
+
+      if (config.wailsVersion === 3) {
+        content = content.replace(
+          /app\.Window\.NewWithOptions\(application\.WebviewWindowOptions\{/,
+          '$&\n\t\tHidden: StartHiddenAtLogin(),'
+        );
+      } else {
+        content = content.replace(
+          /wails\.Run\(\s*&options\.App\s*\{/,
+          '$&\n\t\tStartHidden: StartHiddenAtLogin(),'
+        );
+      }
+      await fse.writeFile(mainGoPath, content);
+    }
 
     spinner.succeed('Startup/auto-launch support added ');
   } catch (error) {
@@ -188,10 +348,51 @@ export async function applyClipboard(config: GeneratorConfig): Promise<void> {
   const spinner = ora('Adding clipboard utilities...').start();
   
   try {
-    const clipboardPath = join(config.projectPath, 'clipboard.go');
-    const clipboardCode = await readTemplate('app-features/clipboard.go', config.wailsVersion);
+    // Bindings, the shared history store, rich-format backends, sensitive copies and the change monitor
+    const clipboardFiles = [
+      'clipboard.go',
+      'clipboard_history.go',
+      'clipboard_formats.go',
+      'clipboard_formats_win32.go',
+      'clipboard_formats_linux.go',
+      'clipboard_formats_windows.go',
+      'clipboard_formats_darwin.go',
+      'clipboard_formats_other.go',
+      'clipboard_sensitive.go',
+      'clipboard_sensitive_windows.go',
+      'clipboard_sensitive_darwin.go',
+      'clipboard_sensitive_other.go',
+      'clipboard_monitor.go',
+      'clipboard_monitor_linux.go',
+      'clipboard_monitor_windows.go',
+      'clipboard_monitor_other.go',
+    ];
+    for (const clipboardFile of clipboardFiles) {
+      const clipboardCode = (await readTemplate(`app-features/${clipboardFile}`, config.wailsVersion))
+        .replace(/{{PROJECT_NAME}}/g, config.projectName);
+      await fse.writeFile(join(config.projectPath, clipboardFile), clipboardCode);
+    }
+
+    if (config.features.testingBackend) {
+      for (const testFile of ['clipboard_history_test.go', 'clipboard_formats_test.go', 'clipboard_sensitive_test.go', 'clipboard_monitor_test.go']) {
+        const testGoCode = await readTemplate(`app-features/${testFile}`, config.wailsVersion);
+        await fse.writeFile(join(config.projectPath, testFile), testGoCode);
+      }
+    }
+
+    // v3 exposes the clipboard as a service rather than App methods
+    if (config.wailsVersion === 3 && !(await mainGoContains(config.projectPath, 'ClipboardService'))) {
+      await patchMainGo(config.projectPath, 3, {
+        addService: '&ClipboardService{}',
+      });
+    }
 
-    await fse.writeFile(clipboardPath, clipboardCode);
+    // v2 starts the clipboard monitor for the history once the runtime context exists
+    // and, since it never cancels that context, stops it (and wl-paste) from OnShutdown
+    if (config.wailsVersion === 2) {
+      await patchAppLifecycle(config.projectPath, 'startup', 'a.startClipboardHistory()');
+      await patchAppLifecycle(config.projectPath, 'shutdown', 'a.StopClipboardMonitor()');
+    }
 
     // Create frontend example
     const frontendExampleDir = join(config.projectPath, 'frontend-examples');
@@ -214,21 +415,62 @@ export async function applyFileWatcher(config: GeneratorConfig): Promise<void> {
   const spinner = ora('Adding file system watcher...').start();
   
   try {
-    const watcherGoPath = join(config.projectPath, 'filewatcher.go');
-    const watcherGoCode = await readTemplate('app-features/filewatcher.go', config.wailsVersion);
-
-    await fse.writeFile(watcherGoPath, watcherGoCode);
-
-    // Add Go dependency note
-    const goModPath = join(config.projectPath, 'go.mod');
-    if (await fse.pathExists(goModPath)) {
-      const goModContent = await fse.readFile(goModPath, 'utf-8');
-      if (!goModContent.includes('fsnotify')) {
-        const note = `\n// For production file watching, add:\n// github.com/fsnotify/fsnotify v1.7.0\n`;
-        await fse.appendFile(goModPath, note);
+    // Bindings, the fsnotify-backed recursive watcher, its event debouncer, path filters,
+    // limit errors, the polling backend for network filesystems and watch persistence
+    const watcherFiles = [
+      'filewatcher.go',
+      'filewatcher_core.go',
+      'filewatcher_debounce.go',
+      'filewatcher_filter.go',
+      'filewatcher_limits_linux.go',
+      'filewatcher_limits_other.go',
+      'filewatcher_poll.go',
+      'filewatcher_fs_linux.go',
+      'filewatcher_fs_darwin.go',
+      'filewatcher_fs_other.go',
+      'filewatcher_store.go',
+    ];
+    for (const watcherFile of watcherFiles) {
+      const watcherGoCode = (await readTemplate(`app-features/${watcherFile}`, config.wailsVersion))
+        .replace(/{{PROJECT_NAME}}/g, config.projectName);
+      await fse.writeFile(join(config.projectPath, watcherFile), watcherGoCode);
+    }
+
+    if (config.features.testingBackend) {
+      const watcherTests = [
+        'filewatcher_test.go',
+        'filewatcher_debounce_test.go',
+        'filewatcher_filter_test.go',
+        'filewatcher_limits_linux_test.go',
+        'filewatcher_poll_test.go',
+        'filewatcher_store_test.go',
+      ];
+      for (const testFile of watcherTests) {
+        const testGoCode = await readTemplate(`app-features/${testFile}`, config.wailsVersion);
+        await fse.writeFile(join(config.projectPath, testFile), testGoCode);
       }
     }
 
+    // The watcher is built on fsnotify and matches include/exclude globs with doublestar
+    await addGoRequires(config.projectPath, {
+      'github.com/fsnotify/fsnotify': 'v1.8.0',
+      'github.com/bmatcuk/doublestar/v4': 'v4.6.1',
+    });
+
+    // v3 exposes the watcher as a service so it starts and closes with the app
+    if (config.wailsVersion === 3 && !(await mainGoContains(config.projectPath, 'FileWatcherService'))) {
+      await patchMainGo(config.projectPath, 3, {
+        addService: '&FileWatcherService{}',
+      });
+    }
+
+    // v2 restores persisted watches at startup and, since it never cancels the
+    // startup context, closes the watcher from OnShutdown
+    if (config.wailsVersion === 2) {
+      await patchAppLifecycle(config.projectPath, 'startup', 'a.startFileWatcher()');
+      await patchAppLifecycle(config.projectPath, 'shutdown', 'a.StopWatching()');
+    }
+
     // Create frontend helper
     const frontendExampleDir = join(config.projectPath, 'frontend-examples');
     await fse.ensureDir(frontendExampleDir);
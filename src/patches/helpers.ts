@@ -200,3 +200,118 @@ export async function mainGoContains(projectPath: string, pattern: string | RegE
     return pattern.test(content);
   }
 }
+
+/**
+ * Adds a call to the Wails v2 App's startup or shutdown method in app.go.
+ * The shutdown method is not part of the Wails template, so it is created and
+ * registered as OnShutdown in main.go the first time.
+ *
+ * @param projectPath - Absolute path to the project
+ * @param hook - 'startup' (runs once the runtime context exists) or 'shutdown'
+ * @param call - Go statement to add, e.g. 'a.StopWatching()'
+ *
+ * @example
+ * await patchAppLifecycle(projectPath, 'shutdown', 'a.StopWatching()');
+ */
+export async function patchAppLifecycle(
+  projectPath: string,
+  hook: 'startup' | 'shutdown',
+  call: string
+): Promise<void> {
+  const appGoPath = join(projectPath, 'app.go');
+  if (!(await fse.pathExists(appGoPath))) {
+    return;
+  }
+
+  let appContent = await fse.readFile(appGoPath, 'utf-8');
+  if (appContent.includes(call)) {
+    return;
+  }
+
+  if (hook === 'shutdown' && !appContent.includes('func (a *App) shutdown(ctx context.Context)')) {
+    appContent += `\n// shutdown is called when the app is closing, after the frontend is destroyed\nfunc (a *App) shutdown(ctx context.Context) {\n}\n`;
+
+    const mainGoPath = join(projectPath, 'main.go');
+    if (await fse.pathExists(mainGoPath)) {
+      let mainContent = await fse.readFile(mainGoPath, 'utf-8');
+      if (!mainContent.includes('OnShutdown:')) {
+        mainContent = /OnStartup:\s*app\.startup,/.test(mainContent)
+          ? mainContent.replace(/(OnStartup:\s*app\.startup,)/, '$1\n\t\tOnShutdown: app.shutdown,')
+          : mainContent.replace(/(wails\.Run\(\s*&options\.App\s*\{)/, '$1\n\t\tOnShutdown: app.shutdown,');
+        await fse.writeFile(mainGoPath, mainContent);
+      }
+    }
+  }
+
+  const body = hook === 'startup'
+    ? /(func \(a \*App\) startup\(ctx context\.Context\) \{\s*a\.ctx = ctx)/
+    : /(func \(a \*App\) shutdown\(ctx context\.Context\) \{)/;
+  appContent = appContent.replace(body, `$1\n\t${call}`);
+  await fse.writeFile(appGoPath, appContent);
+}
+
+/**
+ * Sets callbacks in the Wails v2 `mac.Options` of main.go, adding the Mac
+ * block and its import if the project has none. Fields already set are kept.
+ *
+ * @param projectPath - Absolute path to the project
+ * @param fields - Field names mapped to Go expressions, e.g. { OnUrlOpen: 'app.onMacURLOpen' }
+ *
+ * @example
+ * await patchMacOptions(projectPath, { OnUrlOpen: 'app.onMacURLOpen' });
+ */
+export async function patchMacOptions(projectPath: string, fields: Record<string, string>): Promise<void> {
+  const mainGoPath = join(projectPath, 'main.go');
+  if (!(await fse.pathExists(mainGoPath))) {
+    return;
+  }
+
+  let content = await fse.readFile(mainGoPath, 'utf-8');
+  const missing = Object.entries(fields).filter(([name]) => !new RegExp(`\\b${name}:`).test(content));
+  if (missing.length === 0) {
+    return;
+  }
+  const lines = missing.map(([name, value]) => `\n\t\t\t${name}: ${value},`).join('');
+
+  if (/Mac:\s*&mac\.Options\s*\{/.test(content)) {
+    content = content.replace(/(Mac:\s*&mac\.Options\s*\{)/, `$1${lines}`);
+  } else {
+    const block = `\n\t\tMac: &mac.Options{${lines}\n\t\t},`;
+    content = /OnStartup:\s*app\.startup,/.test(content)
+      ? content.replace(/(OnStartup:\s*app\.startup,)/, `$1${block}`)
+      : content.replace(/(wails\.Run\(\s*&options\.App\s*\{)/, `$1${block}`);
+  }
+
+  if (!content.includes('"github.com/wailsapp/wails/v2/pkg/options/mac"')) {
+    content = content.replace(
+      /(\n\s*"github\.com\/wailsapp\/wails\/v2\/pkg\/options")/,
+      '$1\n\t"github.com/wailsapp/wails/v2/pkg/options/mac"'
+    );
+  }
+  await fse.writeFile(mainGoPath, content);
+}
+
+/**
+ * Declares Go module requirements in go.mod at pinned versions, so the
+ * generated code builds without a manual `go get`. go.sum is filled in by the
+ * `go mod tidy` run after all features are applied.
+ *
+ * @example
+ * await addGoRequires(projectPath, { 'github.com/fsnotify/fsnotify': 'v1.8.0' });
+ */
+export async function addGoRequires(projectPath: string, requires: Record<string, string>): Promise<void> {
+  const goModPath = join(projectPath, 'go.mod');
+  if (!(await fse.pathExists(goModPath))) {
+    return;
+  }
+
+  let content = await fse.readFile(goModPath, 'utf-8');
+  for (const [modulePath, version] of Object.entries(requires)) {
+    const escaped = modulePath.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+    if (new RegExp(`^\\s*(require\\s+)?${escaped}\\s`, 'm').test(content)) {
+      continue;
+    }
+    content = content.replace(/\n*$/, `\n\nrequire ${modulePath} ${version}\n`);
+  }
+  await fse.writeFile(goModPath, content);
+}
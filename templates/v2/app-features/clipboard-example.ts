@@ -1,5 +1,6 @@
 // Clipboard Utilities Example
-import { GetClipboardText, SetClipboardText, CopyToClipboard, PasteFromClipboard } from '../wailsjs/go/main/App'
+import { GetClipboardText, SetClipboardText, CopyToClipboard, PasteFromClipboard, SetClipboardHistoryEnabled, GetClipboardHistory, PinClipboardEntry, ClearClipboardHistory, GetClipboardFormats, GetClipboardHTML, GetClipboardImage, GetClipboardFiles, CopySensitive, StartClipboardMonitor, StopClipboardMonitor } from '../wailsjs/go/main/App'
+import { EventsOn } from '../wailsjs/runtime/runtime'
 
 export async function copyText(text: string) {
   try {
@@ -48,3 +49,57 @@ export async function handlePasteButton() {
   // Use the pasted text
   return text
 }
+
+// Example: Clipboard history is off until the user opts in, e.g. from a settings toggle
+export async function setHistoryEnabled(enabled: boolean) {
+  await SetClipboardHistoryEnabled(enabled)
+}
+
+// Example: Clipboard history (newest first, pinned entries survive purges and clears)
+export async function searchHistory(query: string = '') {
+  return await GetClipboardHistory(query)
+}
+
+export async function togglePin(id: number, pinned: boolean) {
+  await PinClipboardEntry(id, pinned)
+}
+
+export async function clearHistory() {
+  await ClearClipboardHistory()
+}
+
+// Example: Paste the richest format available (Linux needs wl-clipboard or xclip; macOS uses osascript)
+export async function pasteRich() {
+  const formats = await GetClipboardFormats()
+  if (formats.includes('image/png')) {
+    return { type: 'image', src: 'data:image/png;base64,' + await GetClipboardImage() }
+  }
+  if (formats.includes('text/uri-list')) {
+    return { type: 'files', paths: await GetClipboardFiles() }
+  }
+  if (formats.includes('text/html')) {
+    return { type: 'html', html: await GetClipboardHTML() }
+  }
+  return { type: 'text', text: await GetClipboardText() }
+}
+
+// Example: Copy a password; it is cleared after 30 seconds unless the user copied something else
+export async function copySecret(secret: string) {
+  await CopySensitive(secret, 30)
+}
+
+export interface ClipboardChange {
+  type: string
+  size: number
+  content?: string
+}
+
+// Example: React to copies made in other apps; content is only sent if enabled in clipboard_monitor.go
+export async function onClipboardChanged(listener: (change: ClipboardChange) => void) {
+  const unsubscribe = EventsOn('clipboard:changed', (change: ClipboardChange) => listener(change))
+  await StartClipboardMonitor()
+  return async () => {
+    unsubscribe()
+    await StopClipboardMonitor()
+  }
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
@@ -12,12 +15,19 @@ func (a *App) GetClipboardText() (string, error) {
 
 // SetClipboardText writes text to the system clipboard
 func (a *App) SetClipboardText(text string) error {
-	return runtime.ClipboardSetText(a.ctx, text)
+	if err := runtime.ClipboardSetText(a.ctx, text); err != nil {
+		return err
+	}
+	// The copy succeeded; a history that cannot be saved must not fail it
+	if err := sharedClipboardHistory().record(text, ClipboardSourceApp); err != nil {
+		fmt.Println("clipboard history:", err)
+	}
+	return nil
 }
 
 // CopyToClipboard is a helper that copies text and returns success status
 func (a *App) CopyToClipboard(text string) bool {
-	err := runtime.ClipboardSetText(a.ctx, text)
+	err := a.SetClipboardText(text)
 	return err == nil
 }
 
@@ -29,3 +39,135 @@ func (a *App) PasteFromClipboard() string {
 	}
 	return text
 }
+
+// CopySensitive copies a secret and clears it after ttlSeconds (default 30)
+// unless the clipboard changed meanwhile; it is never added to the history
+func (a *App) CopySensitive(text string, ttlSeconds int) error {
+	return sensitiveClipboard.copySecret(text, time.Duration(ttlSeconds)*time.Second,
+		func(value string) error {
+			return runtime.ClipboardSetText(a.ctx, value)
+		},
+		a.readClipboardText)
+}
+
+// GetClipboardFormats lists the formats currently on the clipboard
+func (a *App) GetClipboardFormats() ([]string, error) {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		// Only plain text is reachable through the Wails API
+		if text, _ := runtime.ClipboardGetText(a.ctx); text != "" {
+			return []string{ClipboardFormatText}, nil
+		}
+		return []string{}, nil
+	}
+	return clipboardFormats(backend)
+}
+
+// GetClipboardHTML reads an HTML fragment from the clipboard
+func (a *App) GetClipboardHTML() (string, error) {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return "", err
+	}
+	return readClipboardHTML(backend)
+}
+
+// SetClipboardHTML writes an HTML fragment to the clipboard
+func (a *App) SetClipboardHTML(html string) error {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return err
+	}
+	return writeClipboardHTML(backend, html)
+}
+
+// GetClipboardImage reads a PNG from the clipboard as base64
+func (a *App) GetClipboardImage() (string, error) {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return "", err
+	}
+	return readClipboardImage(backend)
+}
+
+// SetClipboardImage writes a base64 PNG (or PNG data URL) to the clipboard
+func (a *App) SetClipboardImage(encoded string) error {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return err
+	}
+	return writeClipboardImage(backend, encoded)
+}
+
+// GetClipboardFiles reads copied file paths from the clipboard
+func (a *App) GetClipboardFiles() ([]string, error) {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return nil, err
+	}
+	return readClipboardFiles(backend)
+}
+
+// SetClipboardFiles puts file paths on the clipboard as a URI list
+func (a *App) SetClipboardFiles(paths []string) error {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return err
+	}
+	return writeClipboardFiles(backend, paths)
+}
+
+// readClipboardText adapts the Wails text API for the monitor and sensitive copies
+func (a *App) readClipboardText() (string, bool) {
+	text, err := runtime.ClipboardGetText(a.ctx)
+	return text, err == nil
+}
+
+// StartClipboardMonitor emits ClipboardChangedEvent whenever the clipboard changes
+func (a *App) StartClipboardMonitor() {
+	clipboardWatcher.start(a.ctx, a.readClipboardText, func(change ClipboardChange) {
+		runtime.EventsEmit(a.ctx, ClipboardChangedEvent, change)
+	})
+}
+
+// StopClipboardMonitor stops change events, and external history recording with them
+func (a *App) StopClipboardMonitor() {
+	clipboardWatcher.stop()
+}
+
+// IsClipboardMonitorRunning reports whether change events are being emitted
+func (a *App) IsClipboardMonitorRunning() bool {
+	return clipboardWatcher.running()
+}
+
+// startClipboardHistory starts the monitor when external changes are recorded
+func (a *App) startClipboardHistory() {
+	if clipboardHistoryOptions.PollExternal {
+		a.StartClipboardMonitor()
+	}
+}
+
+// SetClipboardHistoryEnabled opts in to, or out of, recording copied text
+func (a *App) SetClipboardHistoryEnabled(enabled bool) error {
+	return sharedClipboardHistory().setEnabled(enabled)
+}
+
+// IsClipboardHistoryEnabled reports whether copied text is being recorded
+func (a *App) IsClipboardHistoryEnabled() bool {
+	return sharedClipboardHistory().enabled()
+}
+
+// GetClipboardHistory returns history entries matching query, newest first
+func (a *App) GetClipboardHistory(query string) []ClipboardEntry {
+	return sharedClipboardHistory().search(query)
+}
+
+// PinClipboardEntry pins or unpins a history entry
+func (a *App) PinClipboardEntry(id uint64, pinned bool) error {
+	return sharedClipboardHistory().pin(id, pinned)
+}
+
+// ClearClipboardHistory removes all entries except pinned ones
+func (a *App) ClearClipboardHistory() error {
+	return sharedClipboardHistory().clear()
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Clipboard history sources
+const (
+	ClipboardSourceApp      = "app"
+	ClipboardSourceExternal = "external"
+)
+
+// ErrClipboardEntryNotFound is returned when pinning an unknown entry
+var ErrClipboardEntryNotFound = errors.New("clipboard history entry not found")
+
+// ClipboardEntry is one recorded clipboard value
+type ClipboardEntry struct {
+	ID       uint64    `json:"id"`
+	Text     string    `json:"text"`
+	Source   string    `json:"source"`
+	CopiedAt time.Time `json:"copiedAt"`
+	Pinned   bool      `json:"pinned"`
+}
+
+// ClipboardHistoryOptions configures what is recorded and for how long
+type ClipboardHistoryOptions struct {
+	// Enabled records text copied through the app. History is opt-in since
+	// entries are stored unencrypted; enable it here or, once the user agrees,
+	// with SetClipboardHistoryEnabled, whose choice is remembered.
+	Enabled bool
+	// MaxEntries bounds the unpinned history; pinned entries are always kept
+	MaxEntries int
+	// MaxAge purges unpinned entries older than this (0 keeps them forever)
+	MaxAge time.Duration
+	// PollExternal also records changes made outside the app, as seen by the clipboard monitor
+	PollExternal bool
+}
+
+// clipboardHistoryFile is the persisted form of the history. Enabled keeps the
+// user's opt-in across restarts and overrides ClipboardHistoryOptions.Enabled.
+type clipboardHistoryFile struct {
+	Enabled bool             `json:"enabled"`
+	Entries []ClipboardEntry `json:"entries"`
+}
+
+// clipboardHistoryOptions can be adjusted before the app starts
+var clipboardHistoryOptions = ClipboardHistoryOptions{
+	Enabled:      false,
+	MaxEntries:   100,
+	MaxAge:       7 * 24 * time.Hour,
+	PollExternal: false,
+}
+
+// clipboardHistory is a bounded, de-duplicated list of entries, newest first
+type clipboardHistory struct {
+	mu       sync.Mutex
+	options  ClipboardHistoryOptions
+	path     string
+	entries  []ClipboardEntry
+	nextID   uint64
+	lastSeen string
+	now      func() time.Time
+}
+
+var (
+	clipboardHistoryStore *clipboardHistory
+	clipboardHistoryOnce  sync.Once
+)
+
+// sharedClipboardHistory returns the shared clipboard history, loading it on first use
+func sharedClipboardHistory() *clipboardHistory {
+	clipboardHistoryOnce.Do(func() {
+		path, err := clipboardHistoryPath()
+		if err != nil {
+			path = ""
+		}
+		clipboardHistoryStore = newClipboardHistory(clipboardHistoryOptions, path)
+	})
+	return clipboardHistoryStore
+}
+
+// clipboardHistoryPath returns where the history is persisted
+func clipboardHistoryPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".{{PROJECT_NAME}}", "clipboard-history.json"), nil
+}
+
+// newClipboardHistory creates a history persisted at path ("" keeps it in memory)
+func newClipboardHistory(options ClipboardHistoryOptions, path string) *clipboardHistory {
+	h := &clipboardHistory{options: options, path: path, nextID: 1, now: time.Now}
+	h.load()
+	return h
+}
+
+// load reads the persisted opt-in and entries, ignoring a missing or corrupt file
+func (h *clipboardHistory) load() {
+	if h.path == "" {
+		return
+	}
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		return
+	}
+	var file clipboardHistoryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return
+	}
+
+	h.options.Enabled = file.Enabled
+	h.entries = file.Entries
+	for _, entry := range h.entries {
+		if entry.ID >= h.nextID {
+			h.nextID = entry.ID + 1
+		}
+	}
+	if len(h.entries) > 0 {
+		h.lastSeen = h.entries[0].Text
+	}
+}
+
+// save persists the opt-in and the entries; the file is private since it may hold secrets
+func (h *clipboardHistory) save() error {
+	if h.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(clipboardHistoryFile{Enabled: h.options.Enabled, Entries: h.entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(h.path, data, 0600)
+}
+
+// record adds text to the front of the history, moving an existing copy instead
+// of duplicating it
+func (h *clipboardHistory) record(text, source string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastSeen = text
+	if !h.options.Enabled || text == "" {
+		return nil
+	}
+
+	entry := ClipboardEntry{ID: h.nextID, Text: text, Source: source, CopiedAt: h.now()}
+	for i, existing := range h.entries {
+		if existing.Text == text {
+			entry.ID = existing.ID
+			entry.Pinned = existing.Pinned
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			break
+		}
+	}
+	if entry.ID == h.nextID {
+		h.nextID++
+	}
+
+	h.entries = append([]ClipboardEntry{entry}, h.entries...)
+	h.purge()
+	return h.save()
+}
+
+// setEnabled turns recording on or off and persists the choice; existing
+// entries are kept until cleared
+func (h *clipboardHistory) setEnabled(enabled bool) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.options.Enabled = enabled
+	return h.save()
+}
+
+// enabled reports whether copies are being recorded
+func (h *clipboardHistory) enabled() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.options.Enabled
+}
+
+// purge drops expired unpinned entries, then the oldest unpinned beyond MaxEntries
+func (h *clipboardHistory) purge() {
+	cutoff := h.now().Add(-h.options.MaxAge)
+	kept := h.entries[:0]
+	unpinned := 0
+	for _, entry := range h.entries {
+		if !entry.Pinned {
+			if h.options.MaxAge > 0 && entry.CopiedAt.Before(cutoff) {
+				continue
+			}
+			if h.options.MaxEntries > 0 && unpinned >= h.options.MaxEntries {
+				continue
+			}
+			unpinned++
+		}
+		kept = append(kept, entry)
+	}
+	h.entries = kept
+}
+
+// search returns entries containing query (case-insensitive), newest first
+func (h *clipboardHistory) search(query string) []ClipboardEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.purge()
+	query = strings.ToLower(query)
+	results := []ClipboardEntry{}
+	for _, entry := range h.entries {
+		if query == "" || strings.Contains(strings.ToLower(entry.Text), query) {
+			results = append(results, entry)
+		}
+	}
+	return results
+}
+
+// pin marks an entry as pinned so purges and ClearClipboardHistory keep it
+func (h *clipboardHistory) pin(id uint64, pinned bool) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range h.entries {
+		if h.entries[i].ID == id {
+			h.entries[i].Pinned = pinned
+			return h.save()
+		}
+	}
+	return ErrClipboardEntryNotFound
+}
+
+// clear removes all unpinned entries
+func (h *clipboardHistory) clear() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	kept := h.entries[:0]
+	for _, entry := range h.entries {
+		if entry.Pinned {
+			kept = append(kept, entry)
+		}
+	}
+	h.entries = kept
+	return h.save()
+}
+
+// recordExternal records a change seen by the clipboard monitor, unless it is
+// the app's own latest copy
+func (h *clipboardHistory) recordExternal(text string) error {
+	h.mu.Lock()
+	changed := text != h.lastSeen
+	h.mu.Unlock()
+	if !changed {
+		return nil
+	}
+	return h.record(text, ClipboardSourceExternal)
+}
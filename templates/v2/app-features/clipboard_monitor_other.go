@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package main
+
+import "context"
+
+// watchClipboard has no change notifications here, so the monitor polls
+func watchClipboard(ctx context.Context) (<-chan struct{}, error) {
+	return nil, errClipboardWatchUnsupported
+}
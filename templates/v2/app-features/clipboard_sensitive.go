@@ -0,0 +1,98 @@
+package main
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// defaultSensitiveTTL applies when CopySensitive is called without a TTL
+const defaultSensitiveTTL = 30 * time.Second
+
+// concealedPasteboardTypes mark a macOS pasteboard entry as a short-lived
+// secret so clipboard managers neither record nor show it (nspasteboard.org)
+var concealedPasteboardTypes = []string{"org.nspasteboard.ConcealedType", "org.nspasteboard.TransientType"}
+
+// sensitiveClipboardState remembers the hash (never the text) of the last
+// sensitive copy so it is cleared only while the clipboard still holds it
+type sensitiveClipboardState struct {
+	mu      sync.Mutex
+	hash    [sha256.Size]byte
+	active  bool
+	timer   *time.Timer
+	conceal func(text string) error
+}
+
+var sensitiveClipboard = sensitiveClipboardState{conceal: writeConcealedText}
+
+// multiTargetClipboard is implemented by backends that can publish several
+// formats under one clipboard ownership, which concealment markers require.
+// The macOS pasteboard backend does; wl-copy and xclip serve a single target
+// per process, so Linux cannot add KDE's x-kde-passwordManagerHint.
+type multiTargetClipboard interface {
+	WriteTargets(targets map[string][]byte) error
+}
+
+// writeConcealedTo publishes text with the concealed pasteboard types, or
+// returns ErrClipboardFormatUnsupported if the backend offers one target only
+func writeConcealedTo(b clipboardBackend, text string) error {
+	multi, ok := b.(multiTargetClipboard)
+	if !ok {
+		return ErrClipboardFormatUnsupported
+	}
+	targets := map[string][]byte{ClipboardFormatText: []byte(text)}
+	for _, marker := range concealedPasteboardTypes {
+		targets[marker] = []byte{}
+	}
+	return multi.WriteTargets(targets)
+}
+
+// copySecret writes text, concealed from clipboard managers where the platform
+// allows it, and clears it after ttl unless it was replaced meanwhile
+func (s *sensitiveClipboardState) copySecret(text string, ttl time.Duration, write func(string) error, read func() (string, bool)) error {
+	if ttl <= 0 {
+		ttl = defaultSensitiveTTL
+	}
+	if err := s.conceal(text); err != nil {
+		if err := write(text); err != nil {
+			return err
+		}
+	}
+
+	hash := sha256.Sum256([]byte(text))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.hash = hash
+	s.active = true
+	s.timer = time.AfterFunc(ttl, func() {
+		s.expire(hash, write, read)
+	})
+	return nil
+}
+
+// expire clears the clipboard if it still holds the sensitive copy
+func (s *sensitiveClipboardState) expire(hash [sha256.Size]byte, write func(string) error, read func() (string, bool)) {
+	s.mu.Lock()
+	if !s.active || s.hash != hash {
+		s.mu.Unlock()
+		return
+	}
+	s.active = false
+	s.mu.Unlock()
+
+	if current, ok := read(); ok && sha256.Sum256([]byte(current)) == hash {
+		write("")
+	}
+}
+
+// matches reports whether text is the pending sensitive copy, so the history
+// poller does not record it
+func (s *sensitiveClipboardState) matches(text string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active && sha256.Sum256([]byte(text)) == s.hash
+}
@@ -0,0 +1,13 @@
+//go:build darwin
+
+package main
+
+// writeConcealedText copies text together with the concealed pasteboard
+// types, which clipboard managers such as Maccy and Alfred honour
+func writeConcealedText(text string) error {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return err
+	}
+	return writeConcealedTo(backend, text)
+}
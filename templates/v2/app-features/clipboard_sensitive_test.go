@@ -0,0 +1,116 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTextClipboard stands in for the Wails text clipboard
+type fakeTextClipboard struct {
+	mu   sync.Mutex
+	text string
+}
+
+func (f *fakeTextClipboard) write(text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.text = text
+	return nil
+}
+
+func (f *fakeTextClipboard) read() (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.text, true
+}
+
+// multiTargetFake is a fakeClipboard that can also publish several targets
+type multiTargetFake struct {
+	*fakeClipboard
+}
+
+func (f multiTargetFake) WriteTargets(targets map[string][]byte) error {
+	f.data = targets
+	return nil
+}
+
+func newTestSensitiveClipboard() *sensitiveClipboardState {
+	return &sensitiveClipboardState{conceal: func(string) error { return ErrClipboardFormatUnsupported }}
+}
+
+func waitForClipboard(t *testing.T, clipboard *fakeTextClipboard, want string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if got, _ := clipboard.read(); got == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	got, _ := clipboard.read()
+	t.Fatalf("clipboard = %q, want %q", got, want)
+}
+
+func TestCopySensitiveClearsAfterTTL(t *testing.T) {
+	clipboard := &fakeTextClipboard{}
+	state := newTestSensitiveClipboard()
+
+	if err := state.copySecret("hunter2", 20*time.Millisecond, clipboard.write, clipboard.read); err != nil {
+		t.Fatal(err)
+	}
+	if !state.matches("hunter2") {
+		t.Error("matches(secret) = false before expiry")
+	}
+	waitForClipboard(t, clipboard, "")
+	if state.matches("hunter2") {
+		t.Error("matches(secret) = true after expiry")
+	}
+}
+
+func TestCopySensitiveKeepsReplacedContent(t *testing.T) {
+	clipboard := &fakeTextClipboard{}
+	state := newTestSensitiveClipboard()
+
+	if err := state.copySecret("hunter2", 20*time.Millisecond, clipboard.write, clipboard.read); err != nil {
+		t.Fatal(err)
+	}
+	clipboard.write("copied elsewhere")
+
+	time.Sleep(60 * time.Millisecond)
+	if got, _ := clipboard.read(); got != "copied elsewhere" {
+		t.Errorf("clipboard = %q, want the user's newer copy", got)
+	}
+}
+
+func TestCopySensitiveRestartsTimer(t *testing.T) {
+	clipboard := &fakeTextClipboard{}
+	state := newTestSensitiveClipboard()
+
+	state.copySecret("first", 20*time.Millisecond, clipboard.write, clipboard.read)
+	state.copySecret("second", time.Hour, clipboard.write, clipboard.read)
+
+	time.Sleep(60 * time.Millisecond)
+	if got, _ := clipboard.read(); got != "second" {
+		t.Errorf("clipboard = %q, want the pending second secret", got)
+	}
+}
+
+func TestWriteConcealedToAddsConcealedTypes(t *testing.T) {
+	backend := multiTargetFake{newFakeClipboard()}
+	if err := writeConcealedTo(backend, "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	for _, marker := range concealedPasteboardTypes {
+		if _, ok := backend.data[marker]; !ok {
+			t.Errorf("%s missing from %v", marker, backend.data)
+		}
+	}
+	if got := string(backend.data[ClipboardFormatText]); got != "hunter2" {
+		t.Errorf("text = %q, want the secret", got)
+	}
+
+	if err := writeConcealedTo(newFakeClipboard(), "hunter2"); err != ErrClipboardFormatUnsupported {
+		t.Errorf("single-target backend error = %v, want ErrClipboardFormatUnsupported", err)
+	}
+}
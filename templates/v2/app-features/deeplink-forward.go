@@ -0,0 +1,15 @@
+package main
+
+import "fmt"
+
+// Route deep links and opened files from a second launch through the running instance
+func init() {
+	secondInstanceHooks = append(secondInstanceHooks, func(a *App, msg SecondInstanceMessage) {
+		for _, link := range deepLinkArgs(msg.Args) {
+			if err := a.HandleDeepLink(link); err != nil {
+				fmt.Println(err)
+			}
+		}
+		a.openFiles(fileAssociationArgs(msg.Args, msg.WorkingDir))
+	})
+}
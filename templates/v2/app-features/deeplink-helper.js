@@ -0,0 +1,54 @@
+// Deep Link Helper
+import { DeepLinkReady, AckDeepLinks } from '../wailsjs/go/main/App'
+import { EventsOn } from '../wailsjs/runtime/runtime'
+
+const seen = new Set()
+
+async function deliver(link, listener) {
+  // A link can arrive both as an event and from DeepLinkReady after a reload
+  if (seen.has(link.id)) {
+    return
+  }
+  seen.add(link.id)
+
+  try {
+    await listener(link)
+  } finally {
+    await AckDeepLinks([link.id])
+  }
+}
+
+// Subscribe to deep links, including those received before the page loaded
+export async function onDeepLink(listener) {
+  EventsOn('deeplink', (link) => deliver(link, listener))
+
+  try {
+    const queued = await DeepLinkReady()
+    for (const link of queued || []) {
+      await deliver(link, listener)
+    }
+  } catch (error) {
+    console.error('Failed to fetch queued deep links:', error)
+  }
+}
+
+// Subscribe to documents opened from the file manager or a second launch.
+// Call this before onDeepLink so files from a cold start are not missed.
+export function onOpenFiles(listener) {
+  EventsOn('OpenFiles', listener)
+}
+
+// Example usage
+export async function setupDeepLinks() {
+  onOpenFiles((paths) => {
+    console.log('Open documents:', paths)
+  })
+
+  await onDeepLink((link) => {
+    if (link.host === 'auth') {
+      console.log('Auth callback code:', link.params.code)
+    } else {
+      console.log('Navigate to:', link.path)
+    }
+  })
+}
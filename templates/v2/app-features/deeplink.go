@@ -1,12 +1,41 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
 	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 const (
 	AppProtocol = "{{PROJECT_NAME_LOWER}}" // e.g., myapp://
+
+	// AppFileExtension is the document type the app opens from the file manager
+	AppFileExtension = ".{{PROJECT_NAME_LOWER}}"
+
+	// AppMimeType identifies AppFileExtension documents on Linux
+	AppMimeType = "application/x-{{PROJECT_NAME_LOWER}}"
+
+	// OpenFilesEvent carries paths opened through the file association
+	OpenFilesEvent = "OpenFiles"
+
+	// DeepLinkEvent is emitted to the frontend for unmatched or frontend-bound links
+	DeepLinkEvent = "deeplink"
+
+	// MaxDeepLinkLength limits the size of URLs accepted from the OS
+	MaxDeepLinkLength = 2048
+
+	// maxDeepLinkParams limits the number of query parameters
+	maxDeepLinkParams = 32
 )
 
 // RegisterDeepLink registers the custom URL protocol
@@ -25,33 +54,551 @@ func (a *App) RegisterDeepLink() error {
 
 // registerDeepLinkWindows registers the protocol on Windows
 func (a *App) registerDeepLinkWindows() error {
-	// Windows registry implementation
-	// In production, use: golang.org/x/sys/windows/registry
-	fmt.Println("To register deep link on Windows:")
-	fmt.Printf("Add to registry: HKEY_CLASSES_ROOT\\%s\n", AppProtocol)
+	// Registration is done by the installer, see build/windows/fileassoc.nsh
+	fmt.Println("To register deep links and file types on Windows:")
+	fmt.Println("Include build/windows/fileassoc.nsh in your NSIS installer script")
 	return nil
 }
 
 // registerDeepLinkMacOS registers the protocol on macOS
 func (a *App) registerDeepLinkMacOS() error {
-	// macOS Info.plist configuration
-	fmt.Println("To register deep link on macOS:")
-	fmt.Println("Add CFBundleURLTypes to Info.plist")
+	// Launch Services reads the app bundle's Info.plist, see build/darwin/fileassoc.plist
+	fmt.Println("To register deep links and file types on macOS:")
+	fmt.Println("Merge build/darwin/fileassoc.plist into your Info.plist")
 	return nil
 }
 
-// registerDeepLinkLinux registers the protocol on Linux
+// deepLinkExecQuoter escapes a path for a quoted Exec= argument; desktop files
+// apply string escaping on top of the quoting rules, hence the double backslashes
+var deepLinkExecQuoter = strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "`", "\\\\`", "$", `\\$`, "%", "%%")
+
+// registerDeepLinkLinux registers the protocol and file type with the desktop
 func (a *App) registerDeepLinkLinux() error {
-	// Linux .desktop file configuration
-	fmt.Println("To register deep link on Linux:")
-	fmt.Printf("Add MimeType=%s to .desktop file\n", AppProtocol)
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// Shared MIME database entry for the document type
+	mimeDir := filepath.Join(dataHome, "mime", "packages")
+	if err := os.MkdirAll(mimeDir, 0755); err != nil {
+		return err
+	}
+	mimeXML := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<mime-info xmlns="http://www.freedesktop.org/standards/shared-mime-info">
+	<mime-type type="%s">
+		<comment>{{PROJECT_NAME}} document</comment>
+		<glob pattern="*%s"/>
+	</mime-type>
+</mime-info>
+`, AppMimeType, AppFileExtension)
+	if err := os.WriteFile(filepath.Join(mimeDir, AppProtocol+".xml"), []byte(mimeXML), 0644); err != nil {
+		return err
+	}
+
+	// Desktop entry that handles both the URL scheme and the document type
+	appsDir := filepath.Join(dataHome, "applications")
+	if err := os.MkdirAll(appsDir, 0755); err != nil {
+		return err
+	}
+	desktopName := AppProtocol + "-handler.desktop"
+	desktopEntry := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name={{PROJECT_NAME}}
+Exec="%s" %%U
+Terminal=false
+NoDisplay=true
+MimeType=x-scheme-handler/%s;%s;
+`, deepLinkExecQuoter.Replace(exePath), AppProtocol, AppMimeType)
+	if err := os.WriteFile(filepath.Join(appsDir, desktopName), []byte(desktopEntry), 0644); err != nil {
+		return err
+	}
+
+	// Refresh the caches; these tools are optional on minimal systems
+	exec.Command("update-mime-database", filepath.Join(dataHome, "mime")).Run()
+	exec.Command("update-desktop-database", appsDir).Run()
+	exec.Command("xdg-mime", "default", desktopName, "x-scheme-handler/"+AppProtocol, AppMimeType).Run()
+
 	return nil
 }
 
-// HandleDeepLink processes a deep link URL
-func (a *App) HandleDeepLink(url string) error {
-	fmt.Printf("Handling deep link: %s\n", url)
-	// Parse and handle the URL
-	// Example: myapp://action/param
+// DeepLink is a parsed and validated deep link
+type DeepLink struct {
+	ID     uint64            `json:"id"`
+	URL    string            `json:"url"`
+	Host   string            `json:"host"`
+	Path   string            `json:"path"`
+	Params map[string]string `json:"params"`
+	Route  string            `json:"route,omitempty"`
+}
+
+// DeepLinkHandler handles a matched deep link in Go
+type DeepLinkHandler func(link *DeepLink) error
+
+var (
+	ErrDeepLinkTooLong     = errors.New("deep link exceeds maximum length")
+	ErrDeepLinkScheme      = errors.New("deep link has wrong scheme")
+	ErrDeepLinkHost        = errors.New("deep link host is not allowed")
+	ErrDeepLinkMalformed   = errors.New("deep link is malformed")
+	ErrDeepLinkParamFormat = errors.New("deep link parameter has invalid format")
+)
+
+// deepLinkParamTypes validates typed path parameters such as {id:int}
+var deepLinkParamTypes = map[string]*regexp.Regexp{
+	"string": regexp.MustCompile(`^[^/\\]{1,256}$`),
+	"int":    regexp.MustCompile(`^[0-9]{1,18}$`),
+	"slug":   regexp.MustCompile(`^[a-zA-Z0-9_-]{1,128}$`),
+	"uuid":   regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+}
+
+// deepLinkSegment is one path segment of a route pattern
+type deepLinkSegment struct {
+	literal   string
+	param     string
+	paramType string
+}
+
+// deepLinkRoute is a compiled route pattern
+type deepLinkRoute struct {
+	pattern  string
+	host     string
+	segments []deepLinkSegment
+	query    []string
+	handler  DeepLinkHandler
+}
+
+// DeepLinkRouter dispatches deep links to registered handlers
+type DeepLinkRouter struct {
+	mu           sync.RWMutex
+	scheme       string
+	routes       []*deepLinkRoute
+	allowedHosts map[string]bool
+}
+
+// NewDeepLinkRouter creates a router for the given URL scheme
+func NewDeepLinkRouter(scheme string) *DeepLinkRouter {
+	return &DeepLinkRouter{
+		scheme:       strings.ToLower(scheme),
+		allowedHosts: make(map[string]bool),
+	}
+}
+
+// Handle registers a Go handler for a pattern such as
+// "myapp://open/{id:int}" or "myapp://auth/callback?code=&state="
+func (r *DeepLinkRouter) Handle(pattern string, handler DeepLinkHandler) error {
+	route, err := r.compile(pattern)
+	if err != nil {
+		return err
+	}
+	route.handler = handler
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, route)
+	r.allowedHosts[route.host] = true
 	return nil
 }
+
+// Forward registers a pattern whose links are sent to the frontend as a deeplink event
+func (r *DeepLinkRouter) Forward(pattern string) error {
+	return r.Handle(pattern, nil)
+}
+
+// AllowHost permits links for a host that has no registered route;
+// such links are forwarded to the frontend
+func (r *DeepLinkRouter) AllowHost(host string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.allowedHosts[strings.ToLower(host)] = true
+}
+
+// compile parses a route pattern
+func (r *DeepLinkRouter) compile(pattern string) (*deepLinkRoute, error) {
+	rest, ok := strings.CutPrefix(pattern, r.scheme+"://")
+	if !ok {
+		return nil, fmt.Errorf("pattern %q must start with %s://", pattern, r.scheme)
+	}
+
+	rest, rawQuery, _ := strings.Cut(rest, "?")
+	host, rawPath, _ := strings.Cut(rest, "/")
+	if host == "" {
+		return nil, fmt.Errorf("pattern %q has no host", pattern)
+	}
+
+	route := &deepLinkRoute{pattern: pattern, host: strings.ToLower(host)}
+
+	for _, part := range splitDeepLinkPath(rawPath) {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			name, paramType, found := strings.Cut(part[1:len(part)-1], ":")
+			if !found {
+				paramType = "string"
+			}
+			if _, known := deepLinkParamTypes[paramType]; !known || name == "" {
+				return nil, fmt.Errorf("pattern %q has invalid parameter %q", pattern, part)
+			}
+			route.segments = append(route.segments, deepLinkSegment{param: name, paramType: paramType})
+		} else {
+			route.segments = append(route.segments, deepLinkSegment{literal: part})
+		}
+	}
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if name := strings.TrimSuffix(pair, "="); name != "" {
+			route.query = append(route.query, name)
+		}
+	}
+
+	return route, nil
+}
+
+// Parse validates a raw deep link without dispatching it
+func (r *DeepLinkRouter) Parse(raw string) (*DeepLink, []string, error) {
+	if len(raw) > MaxDeepLinkLength {
+		return nil, nil, ErrDeepLinkTooLong
+	}
+	for _, c := range raw {
+		if unicode.IsControl(c) || unicode.IsSpace(c) {
+			return nil, nil, ErrDeepLinkMalformed
+		}
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrDeepLinkMalformed, err)
+	}
+	if strings.ToLower(u.Scheme) != r.scheme {
+		return nil, nil, ErrDeepLinkScheme
+	}
+	if u.User != nil || u.Port() != "" || u.Opaque != "" {
+		return nil, nil, ErrDeepLinkMalformed
+	}
+
+	host := strings.ToLower(u.Hostname())
+	r.mu.RLock()
+	allowed := r.allowedHosts[host]
+	r.mu.RUnlock()
+	if !allowed {
+		return nil, nil, fmt.Errorf("%w: %q", ErrDeepLinkHost, host)
+	}
+
+	// Split the escaped path first so that %2F and %5C cannot introduce separators
+	var segments []string
+	for _, part := range splitDeepLinkPath(u.EscapedPath()) {
+		decoded, err := url.PathUnescape(part)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrDeepLinkMalformed, err)
+		}
+		if decoded == "." || decoded == ".." || strings.ContainsAny(decoded, "/\\\x00") {
+			return nil, nil, fmt.Errorf("%w: unsafe path segment", ErrDeepLinkMalformed)
+		}
+		segments = append(segments, decoded)
+	}
+
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrDeepLinkMalformed, err)
+	}
+	if len(query) > maxDeepLinkParams {
+		return nil, nil, fmt.Errorf("%w: too many parameters", ErrDeepLinkMalformed)
+	}
+
+	link := &DeepLink{
+		URL:    raw,
+		Host:   host,
+		Path:   "/" + strings.Join(segments, "/"),
+		Params: make(map[string]string, len(query)),
+	}
+	for key, values := range query {
+		if len(values) > 0 {
+			link.Params[key] = values[0]
+		}
+	}
+
+	return link, segments, nil
+}
+
+// Route validates a deep link and dispatches it to the first matching route.
+// It reports handled=false when the link should be forwarded to the frontend.
+// A route whose typed parameters or required query parameters do not fit is
+// skipped; ErrDeepLinkParamFormat is only returned if no other route matches.
+func (r *DeepLinkRouter) Route(raw string) (link *DeepLink, handled bool, err error) {
+	link, segments, err := r.Parse(raw)
+	if err != nil {
+		return nil, false, err
+	}
+
+	r.mu.RLock()
+	routes := r.routes
+	r.mu.RUnlock()
+
+	var paramErr error
+	for _, route := range routes {
+		params, ok, err := route.match(link, segments)
+		if err != nil && paramErr == nil {
+			paramErr = err
+		}
+		if !ok {
+			continue
+		}
+
+		for key, value := range params {
+			link.Params[key] = value
+		}
+		link.Route = route.pattern
+
+		if route.handler == nil {
+			return link, false, nil
+		}
+		return link, true, route.handler(link)
+	}
+
+	if paramErr != nil {
+		return nil, false, paramErr
+	}
+	return link, false, nil
+}
+
+// match checks a parsed link against the route and extracts path parameters.
+// The error explains why a link with the route's shape did not match.
+func (route *deepLinkRoute) match(link *DeepLink, segments []string) (map[string]string, bool, error) {
+	if link.Host != route.host || len(segments) != len(route.segments) {
+		return nil, false, nil
+	}
+
+	params := make(map[string]string)
+	for i, segment := range route.segments {
+		if segment.param == "" {
+			if segments[i] != segment.literal {
+				return nil, false, nil
+			}
+			continue
+		}
+		if !deepLinkParamTypes[segment.paramType].MatchString(segments[i]) {
+			return nil, false, fmt.Errorf("%w: %s", ErrDeepLinkParamFormat, segment.param)
+		}
+		params[segment.param] = segments[i]
+	}
+
+	for _, name := range route.query {
+		if link.Params[name] == "" {
+			return nil, false, fmt.Errorf("%w: missing %s", ErrDeepLinkParamFormat, name)
+		}
+	}
+
+	return params, true, nil
+}
+
+// deepLinkArgs returns the command-line arguments that are deep links for this app
+func deepLinkArgs(args []string) []string {
+	var links []string
+	for _, arg := range args {
+		if strings.HasPrefix(strings.ToLower(arg), AppProtocol+"://") {
+			links = append(links, arg)
+		}
+	}
+	return links
+}
+
+// fileAssociationArgs returns the arguments that are existing AppFileExtension files,
+// resolved against workingDir
+func fileAssociationArgs(args []string, workingDir string) []string {
+	var paths []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") || !strings.EqualFold(filepath.Ext(arg), AppFileExtension) {
+			continue
+		}
+		path := arg
+		if !filepath.IsAbs(path) && workingDir != "" {
+			path = filepath.Join(workingDir, path)
+		}
+		path, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// splitDeepLinkPath splits a path into non-empty segments
+func splitDeepLinkPath(path string) []string {
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// deepLinkQueue holds frontend-bound links until the frontend acknowledges them
+type deepLinkQueue struct {
+	mu      sync.Mutex
+	ready   bool
+	nextID  uint64
+	pending []*DeepLink
+	files   []string
+}
+
+// push queues a link and reports whether the frontend is ready to receive it now
+func (q *deepLinkQueue) push(link *DeepLink) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.nextID++
+	link.ID = q.nextID
+	q.pending = append(q.pending, link)
+	return q.ready
+}
+
+// pushFiles buffers opened files until the frontend is ready; it reports
+// whether the files should be emitted now instead
+func (q *deepLinkQueue) pushFiles(paths []string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.ready {
+		q.files = append(q.files, paths...)
+	}
+	return q.ready
+}
+
+// takeFiles returns and clears the buffered files
+func (q *deepLinkQueue) takeFiles() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	files := q.files
+	q.files = nil
+	return files
+}
+
+// markReady returns every link not yet acknowledged
+func (q *deepLinkQueue) markReady() []*DeepLink {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.ready = true
+	return append([]*DeepLink{}, q.pending...)
+}
+
+// ack drops acknowledged links so they are never delivered again
+func (q *deepLinkQueue) ack(ids []uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	acked := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		acked[id] = true
+	}
+	remaining := q.pending[:0]
+	for _, link := range q.pending {
+		if !acked[link.ID] {
+			remaining = append(remaining, link)
+		}
+	}
+	q.pending = remaining
+}
+
+var (
+	deepLinkRouter     *DeepLinkRouter
+	deepLinkRouterOnce sync.Once
+
+	// deepLinkInbox buffers links until the frontend calls DeepLinkReady
+	deepLinkInbox = &deepLinkQueue{}
+
+	// startupDeepLinks are links the app was launched with (cold start)
+	startupDeepLinks     = deepLinkArgs(os.Args[1:])
+	startupOpenFiles     = fileAssociationArgs(os.Args[1:], "")
+	startupDeepLinksOnce sync.Once
+)
+
+// deepLinks returns the app's router, registering routes on first use
+func (a *App) deepLinks() *DeepLinkRouter {
+	deepLinkRouterOnce.Do(func() {
+		deepLinkRouter = NewDeepLinkRouter(AppProtocol)
+		a.registerDeepLinkRoutes(deepLinkRouter)
+	})
+	return deepLinkRouter
+}
+
+// registerDeepLinkRoutes declares the deep links understood by the app.
+// Routes without a Go handler are forwarded to the frontend.
+func (a *App) registerDeepLinkRoutes(r *DeepLinkRouter) {
+	// Example: myapp://open/42 handled in Go
+	r.Handle(AppProtocol+"://open/{id:int}", func(link *DeepLink) error {
+		fmt.Printf("Opening item %s\n", link.Params["id"])
+		return nil
+	})
+
+	// Example: OAuth callback requiring code and state query parameters
+	r.Forward(AppProtocol + "://auth/callback?code=&state=")
+
+	// Example: any myapp://view/... link is routed by the frontend
+	r.AllowHost("view")
+}
+
+// HandleDeepLink validates a deep link URL and dispatches it
+func (a *App) HandleDeepLink(rawURL string) error {
+	link, handled, err := a.deepLinks().Route(rawURL)
+	if err != nil {
+		return fmt.Errorf("rejected deep link: %w", err)
+	}
+
+	if !handled && deepLinkInbox.push(link) {
+		wailsruntime.EventsEmit(a.ctx, DeepLinkEvent, link)
+	}
+	return nil
+}
+
+// DeepLinkReady is called by the frontend once it listens for deeplink events.
+// It returns every queued link that has not been acknowledged yet.
+func (a *App) DeepLinkReady() []*DeepLink {
+	startupDeepLinksOnce.Do(func() {
+		for _, rawURL := range startupDeepLinks {
+			if err := a.HandleDeepLink(rawURL); err != nil {
+				fmt.Println(err)
+			}
+		}
+		a.openFiles(startupOpenFiles)
+	})
+	links := deepLinkInbox.markReady()
+
+	if files := deepLinkInbox.takeFiles(); len(files) > 0 {
+		wailsruntime.EventsEmit(a.ctx, OpenFilesEvent, files)
+	}
+	return links
+}
+
+// openFiles sends opened documents to the frontend, buffering them until it is ready
+func (a *App) openFiles(paths []string) {
+	if len(paths) > 0 && deepLinkInbox.pushFiles(paths) {
+		wailsruntime.EventsEmit(a.ctx, OpenFilesEvent, paths)
+	}
+}
+
+// onMacURLOpen receives deep links on macOS, where Launch Services sends them
+// as Apple events instead of command-line arguments. It is registered as
+// mac.Options.OnUrlOpen in main.go.
+func (a *App) onMacURLOpen(rawURL string) {
+	if err := a.HandleDeepLink(rawURL); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// onMacFileOpen receives documents opened from Finder on macOS. It is
+// registered as mac.Options.OnFileOpen in main.go.
+func (a *App) onMacFileOpen(path string) {
+	a.openFiles(fileAssociationArgs([]string{path}, ""))
+}
+
+// AckDeepLinks confirms that the frontend has processed the given links
+func (a *App) AckDeepLinks(ids []uint64) {
+	deepLinkInbox.ack(ids)
+}
@@ -1,5 +1,53 @@
 // Native Dialogs Example
-import { OpenFileDialog, OpenDirectoryDialog, SaveFileDialog, ShowInfoDialog, ShowQuestionDialog } from '../wailsjs/go/main/App'
+import { OpenFileDialog, OpenFileDialogWithOptions, OpenDirectoryDialog, SaveFileDialog, SaveFileDialogWithOptions, GetRecentFiles, ShowInfoDialog, ShowQuestionDialog, ShowMessage, ResetDontAskAgain } from '../wailsjs/go/main/App'
+
+export interface DialogFilter {
+  displayName: string
+  // One or more globs separated by semicolons, e.g. "*.png;*.jpg"
+  pattern: string
+}
+
+export interface FileDialogOptions {
+  // A key such as "import" or "export"; the dialog reopens where it was last used for it
+  purpose?: string
+  title?: string
+  // Ignored if the directory no longer exists
+  defaultDirectory?: string
+  // A file name only, without a directory
+  defaultFilename?: string
+  filters?: DialogFilter[]
+  showHiddenFiles?: boolean
+  canCreateDirectories?: boolean
+  // macOS: return the targets of aliases
+  resolvesAliases?: boolean
+}
+
+export interface MessageOptions {
+  // 'info' (default), 'warning', 'error' or 'question'
+  type?: 'info' | 'warning' | 'error' | 'question'
+  title: string
+  message: string
+  // Up to four buttons, shown in order; defaults to OK, or Yes and No for questions
+  buttons?: string[]
+  // Chosen with Enter; defaults to the first button
+  defaultButton?: string
+  // Reported when the dialog is dismissed with Escape or closed
+  cancelButton?: string
+  // A key such as "confirm-trash"; once "don't ask again" is chosen the dialog is skipped
+  dontAskAgain?: string
+  dontAskAgainLabel?: string
+  // A base64 PNG or data URL
+  icon?: string
+}
+
+export interface MessageResult {
+  // Empty if the dialog was dismissed without a cancel button
+  button: string
+  cancelled: boolean
+  dontAskAgain: boolean
+  // The dialog was skipped in favour of a remembered answer
+  remembered: boolean
+}
 
 export async function openFile() {
   try {
@@ -11,6 +59,53 @@ export async function openFile() {
   }
 }
 
+// Open a file with custom options
+export async function openFileWithOptions(options: FileDialogOptions) {
+  try {
+    return await OpenFileDialogWithOptions(options)
+  } catch (error) {
+    console.error('Error opening file:', error)
+  }
+}
+
+// Example: pick an image, starting where the last image was picked, or in directory the first time
+export async function openImage(directory: string) {
+  return openFileWithOptions({
+    purpose: 'images',
+    title: 'Choose an image',
+    defaultDirectory: directory,
+    filters: [
+      { displayName: 'Images', pattern: '*.png;*.jpg;*.jpeg;*.gif' },
+      { displayName: 'All Files', pattern: '*.*' },
+    ],
+  })
+}
+
+// Example: export a CSV report with a suggested name
+export async function exportReport() {
+  try {
+    return await SaveFileDialogWithOptions({
+      purpose: 'export',
+      title: 'Export report',
+      defaultFilename: 'report.csv',
+      filters: [{ displayName: 'CSV Files', pattern: '*.csv' }],
+      canCreateDirectories: true,
+    })
+  } catch (error) {
+    console.error('Error saving file:', error)
+  }
+}
+
+// Files recently chosen in open dialogs, newest first
+export async function recentFiles(): Promise<string[]> {
+  try {
+    return (await GetRecentFiles()) || []
+  } catch (error) {
+    console.error('Error loading recent files:', error)
+    return []
+  }
+}
+
 export async function openDirectory() {
   try {
     const dir = await OpenDirectoryDialog()
@@ -44,3 +139,37 @@ export async function askQuestion() {
     console.error('Error showing question:', error)
   }
 }
+
+// Show a message with custom buttons
+export async function showMessageDialog(options: MessageOptions): Promise<MessageResult | undefined> {
+  try {
+    return await ShowMessage(options)
+  } catch (error) {
+    console.error('Error showing message:', error)
+  }
+}
+
+// Example: confirm moving a file to the trash, letting the user skip the question next time.
+// Only Windows has the "don't ask again" checkbox; elsewhere the question is asked every time.
+export async function confirmTrash(name: string) {
+  const options: MessageOptions = {
+    type: 'question',
+    title: 'Move to Trash',
+    message: `Move ${name} to the trash?`,
+    buttons: ['Move to Trash', 'Cancel'],
+    defaultButton: 'Move to Trash',
+    cancelButton: 'Cancel',
+    dontAskAgain: 'confirm-trash',
+  }
+  const result = await showMessageDialog(options)
+  return result?.button === 'Move to Trash'
+}
+
+// Ask again for a "don't ask again" key, or for every key if it is empty
+export async function resetDontAskAgain(key = '') {
+  try {
+    await ResetDontAskAgain(key)
+  } catch (error) {
+    console.error('Error resetting remembered answers:', error)
+  }
+}
@@ -6,85 +6,178 @@ import (
 
 // OpenFileDialog opens a native file picker dialog
 func (a *App) OpenFileDialog() (string, error) {
-	file, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select File",
-		Filters: []runtime.FileFilter{
-			{
-				DisplayName: "All Files (*.*)",
-				Pattern:     "*.*",
-			},
-		},
+	return a.OpenFileDialogWithOptions(FileDialogOptions{
+		Title:   "Select File",
+		Filters: []DialogFilter{allFilesFilter},
 	})
+}
+
+// OpenFileDialogWithOptions opens a native file picker configured by the frontend
+func (a *App) OpenFileDialogWithOptions(options FileDialogOptions) (string, error) {
+	dialogOptions, err := openDialogOptions(options)
+	if err != nil {
+		return "", err
+	}
+	file, err := runtime.OpenFileDialog(a.ctx, dialogOptions)
+	if err == nil {
+		sharedDialogState().remember(options.Purpose, []string{file}, false, true)
+	}
 	return file, err
 }
 
 // OpenMultipleFilesDialog opens a native file picker for multiple files
 func (a *App) OpenMultipleFilesDialog() ([]string, error) {
-	files, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select Files",
-		Filters: []runtime.FileFilter{
-			{
-				DisplayName: "All Files (*.*)",
-				Pattern:     "*.*",
-			},
-		},
+	return a.OpenMultipleFilesDialogWithOptions(FileDialogOptions{
+		Title:   "Select Files",
+		Filters: []DialogFilter{allFilesFilter},
 	})
+}
+
+// OpenMultipleFilesDialogWithOptions opens a native multi-file picker configured by the frontend
+func (a *App) OpenMultipleFilesDialogWithOptions(options FileDialogOptions) ([]string, error) {
+	dialogOptions, err := openDialogOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	files, err := runtime.OpenMultipleFilesDialog(a.ctx, dialogOptions)
+	if err == nil {
+		sharedDialogState().remember(options.Purpose, files, false, true)
+	}
 	return files, err
 }
 
 // OpenDirectoryDialog opens a native directory picker dialog
 func (a *App) OpenDirectoryDialog() (string, error) {
-	directory, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+	return a.OpenDirectoryDialogWithOptions(FileDialogOptions{
 		Title: "Select Directory",
 	})
+}
+
+// OpenDirectoryDialogWithOptions opens a native directory picker configured by
+// the frontend; filters do not apply
+func (a *App) OpenDirectoryDialogWithOptions(options FileDialogOptions) (string, error) {
+	dialogOptions, err := openDialogOptions(options)
+	if err != nil {
+		return "", err
+	}
+	directory, err := runtime.OpenDirectoryDialog(a.ctx, dialogOptions)
+	if err == nil {
+		sharedDialogState().remember(options.Purpose, []string{directory}, true, false)
+	}
 	return directory, err
 }
 
 // SaveFileDialog opens a native save file dialog
 func (a *App) SaveFileDialog() (string, error) {
-	file, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
-		Title: "Save File",
+	return a.SaveFileDialogWithOptions(FileDialogOptions{
+		Title:           "Save File",
 		DefaultFilename: "untitled.txt",
-		Filters: []runtime.FileFilter{
-			{
-				DisplayName: "Text Files (*.txt)",
-				Pattern:     "*.txt",
-			},
-			{
-				DisplayName: "All Files (*.*)",
-				Pattern:     "*.*",
-			},
+		Filters: []DialogFilter{
+			{DisplayName: "Text Files (*.txt)", Pattern: "*.txt"},
+			allFilesFilter,
 		},
 	})
+}
+
+// SaveFileDialogWithOptions opens a native save file dialog configured by the frontend
+func (a *App) SaveFileDialogWithOptions(options FileDialogOptions) (string, error) {
+	options, err := sharedDialogState().prepare(options)
+	if err != nil {
+		return "", err
+	}
+	file, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:                options.Title,
+		DefaultDirectory:     options.DefaultDirectory,
+		DefaultFilename:      options.DefaultFilename,
+		Filters:              runtimeFileFilters(options.Filters),
+		ShowHiddenFiles:      options.ShowHiddenFiles,
+		CanCreateDirectories: options.CanCreateDirectories,
+	})
+	if err == nil {
+		sharedDialogState().remember(options.Purpose, []string{file}, false, false)
+	}
 	return file, err
 }
 
-// ShowInfoDialog shows an information message dialog
-func (a *App) ShowInfoDialog(title, message string) {
-	runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-		Type:    runtime.InfoDialog,
-		Title:   title,
-		Message: message,
+// GetRecentFiles returns files recently chosen in open dialogs that still exist, newest first
+func (a *App) GetRecentFiles() []string {
+	return sharedDialogState().recentFiles()
+}
+
+// ClearRecentFiles empties the recent-files list
+func (a *App) ClearRecentFiles() error {
+	return sharedDialogState().clearRecentFiles()
+}
+
+// openDialogOptions maps validated options, starting in the directory
+// remembered for their purpose, onto the runtime's open dialog options
+func openDialogOptions(options FileDialogOptions) (runtime.OpenDialogOptions, error) {
+	options, err := sharedDialogState().prepare(options)
+	if err != nil {
+		return runtime.OpenDialogOptions{}, err
+	}
+	return runtime.OpenDialogOptions{
+		Title:                options.Title,
+		DefaultDirectory:     options.DefaultDirectory,
+		DefaultFilename:      options.DefaultFilename,
+		Filters:              runtimeFileFilters(options.Filters),
+		ShowHiddenFiles:      options.ShowHiddenFiles,
+		CanCreateDirectories: options.CanCreateDirectories,
+		ResolvesAliases:      options.ResolvesAliases,
+	}, nil
+}
+
+// runtimeFileFilters converts filters to the runtime's type
+func runtimeFileFilters(filters []DialogFilter) []runtime.FileFilter {
+	converted := make([]runtime.FileFilter, 0, len(filters))
+	for _, filter := range filters {
+		converted = append(converted, runtime.FileFilter{DisplayName: filter.DisplayName, Pattern: filter.Pattern})
+	}
+	return converted
+}
+
+// ShowMessage shows a message dialog with custom buttons and returns which
+// one was chosen. With a DontAskAgain key the choice can be remembered, after
+// which the dialog is skipped; ResetDontAskAgain shows it again.
+func (a *App) ShowMessage(options MessageOptions) (MessageResult, error) {
+	return sharedDialogState().showMessage(options, func(options MessageOptions) (string, bool, error) {
+		if label, dontAskAgain, ok, err := nativeMessageDialog(options); ok {
+			return label, dontAskAgain, err
+		}
+		// The runtime's dialog types use the same names as MessageOptions.Type
+		label, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+			Type:          runtime.DialogType(options.Type),
+			Title:         options.Title,
+			Message:       options.Message,
+			Buttons:       options.Buttons,
+			DefaultButton: options.DefaultButton,
+			CancelButton:  options.CancelButton,
+			Icon:          options.icon,
+		})
+		return label, false, err
 	})
 }
 
+// ResetDontAskAgain shows a message again after "don't ask again" was chosen
+// for its key; an empty key resets every message
+func (a *App) ResetDontAskAgain(key string) error {
+	return sharedDialogState().forgetChoices(key)
+}
+
+// ShowInfoDialog shows an information message dialog
+func (a *App) ShowInfoDialog(title, message string) error {
+	_, err := a.ShowMessage(MessageOptions{Type: MessageTypeInfo, Title: title, Message: message})
+	return err
+}
+
 // ShowErrorDialog shows an error message dialog
-func (a *App) ShowErrorDialog(title, message string) {
-	runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-		Type:    runtime.ErrorDialog,
-		Title:   title,
-		Message: message,
-	})
+func (a *App) ShowErrorDialog(title, message string) error {
+	_, err := a.ShowMessage(MessageOptions{Type: MessageTypeError, Title: title, Message: message})
+	return err
 }
 
-// ShowQuestionDialog shows a question dialog and returns the user's choice
+// ShowQuestionDialog shows a Yes/No question dialog and returns the user's choice
 func (a *App) ShowQuestionDialog(title, message string) (string, error) {
-	result, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-		Type:          runtime.QuestionDialog,
-		Title:         title,
-		Message:       message,
-		Buttons:       []string{"Yes", "No"},
-		DefaultButton: "Yes",
-	})
-	return result, err
+	result, err := a.ShowMessage(MessageOptions{Type: MessageTypeQuestion, Title: title, Message: message})
+	return result.Button, err
 }
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Message dialog types
+const (
+	MessageTypeInfo     = "info"
+	MessageTypeWarning  = "warning"
+	MessageTypeError    = "error"
+	MessageTypeQuestion = "question"
+)
+
+// maxMessageButtons is the most buttons every platform can show
+const maxMessageButtons = 4
+
+// defaultDontAskAgainLabel is shown when DontAskAgainLabel is empty
+const defaultDontAskAgainLabel = "Don't ask again"
+
+// MessageOptions configures ShowMessage
+type MessageOptions struct {
+	// Type is info, warning, error or question; empty means info
+	Type    string `json:"type,omitempty"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	// Buttons are shown in order; empty shows OK, or Yes and No for questions.
+	// Wails v2 on Linux always shows those defaults, and maps OK and Yes to
+	// DefaultButton and No to CancelButton.
+	Buttons []string `json:"buttons,omitempty"`
+	// DefaultButton is chosen with Enter; empty means the first button
+	DefaultButton string `json:"defaultButton,omitempty"`
+	// CancelButton is reported when the dialog is dismissed with Escape or closed
+	CancelButton string `json:"cancelButton,omitempty"`
+	// DontAskAgain is a key such as "confirm-delete". The dialog offers a
+	// "don't ask again" checkbox, and once ticked later calls with the same key
+	// return the remembered button without showing the dialog. Only Windows
+	// task dialogs have the checkbox; elsewhere, or without Common Controls 6,
+	// the dialog is shown without it and the answer is not remembered.
+	DontAskAgain string `json:"dontAskAgain,omitempty"`
+	// DontAskAgainLabel overrides the "Don't ask again" text
+	DontAskAgainLabel string `json:"dontAskAgainLabel,omitempty"`
+	// Icon is a base64 PNG, optionally as a data URL; empty uses the type's icon
+	Icon string `json:"icon,omitempty"`
+
+	icon []byte
+}
+
+// MessageResult is the outcome of ShowMessage
+type MessageResult struct {
+	// Button is the chosen button, or empty if the dialog was dismissed
+	// without a CancelButton
+	Button string `json:"button"`
+	// Cancelled is set for the cancel button and for dismissing the dialog
+	Cancelled bool `json:"cancelled"`
+	// DontAskAgain is set when Button is, or has just become, the remembered answer
+	DontAskAgain bool `json:"dontAskAgain"`
+	// Remembered is set when the dialog was skipped in favour of a remembered answer
+	Remembered bool `json:"remembered"`
+}
+
+// normalize validates options coming from the frontend, fills in default
+// buttons and decodes the icon
+func (o MessageOptions) normalize() (MessageOptions, error) {
+	switch o.Type {
+	case "":
+		o.Type = MessageTypeInfo
+	case MessageTypeInfo, MessageTypeWarning, MessageTypeError, MessageTypeQuestion:
+	default:
+		return o, fmt.Errorf("unknown message type %q", o.Type)
+	}
+
+	if len(o.Buttons) == 0 {
+		if o.Type == MessageTypeQuestion {
+			o.Buttons = []string{"Yes", "No"}
+			if o.CancelButton == "" {
+				o.CancelButton = "No"
+			}
+		} else {
+			o.Buttons = []string{"OK"}
+		}
+	}
+
+	if o.DontAskAgain != "" {
+		if !dialogPurposePattern.MatchString(o.DontAskAgain) {
+			return o, errors.New("dontAskAgain key must be 1-64 letters, digits, '.', '-' or '_'")
+		}
+		if o.DontAskAgainLabel == "" {
+			o.DontAskAgainLabel = defaultDontAskAgainLabel
+		}
+	}
+	if len(o.Buttons) > maxMessageButtons {
+		return o, fmt.Errorf("at most %d buttons can be shown", maxMessageButtons)
+	}
+	seen := make(map[string]bool)
+	for _, button := range o.Buttons {
+		if strings.TrimSpace(button) == "" {
+			return o, errors.New("button labels must not be empty")
+		}
+		if seen[button] {
+			return o, fmt.Errorf("button %q is used twice", button)
+		}
+		seen[button] = true
+	}
+
+	if o.DefaultButton == "" {
+		o.DefaultButton = o.Buttons[0]
+	}
+	if !o.hasButton(o.DefaultButton) {
+		return o, fmt.Errorf("default button %q is not one of the buttons", o.DefaultButton)
+	}
+	if o.CancelButton != "" && !o.hasButton(o.CancelButton) {
+		return o, fmt.Errorf("cancel button %q is not one of the buttons", o.CancelButton)
+	}
+
+	if o.Icon != "" {
+		encoded := o.Icon
+		if i := strings.Index(encoded, ";base64,"); strings.HasPrefix(encoded, "data:") && i >= 0 {
+			encoded = encoded[i+len(";base64,"):]
+		}
+		icon, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return o, fmt.Errorf("icon is not valid base64: %w", err)
+		}
+		o.icon = icon
+	}
+	return o, nil
+}
+
+// hasButton reports whether label is one of the dialog's buttons
+func (o MessageOptions) hasButton(label string) bool {
+	for _, button := range o.Buttons {
+		if button == label {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve maps the label a dialog returned onto the buttons, reporting false
+// if the dialog was dismissed. Runtimes that only show OK or Yes/No, such as
+// Windows message boxes, return their own labels; those map onto the default
+// and cancel buttons.
+func (o MessageOptions) resolve(label string) (string, bool) {
+	for _, button := range o.Buttons {
+		if button == label {
+			return button, true
+		}
+	}
+	for _, button := range o.Buttons {
+		if strings.EqualFold(button, label) {
+			return button, true
+		}
+	}
+	switch strings.ToLower(label) {
+	case "ok", "yes":
+		return o.DefaultButton, true
+	case "no":
+		if o.CancelButton != "" {
+			return o.CancelButton, true
+		}
+	}
+	return o.CancelButton, false
+}
+
+// showMessage validates options, skips the dialog when an answer is
+// remembered for its DontAskAgain key, and otherwise shows it with show, which
+// returns the clicked label ("" when dismissed) and whether "don't ask again"
+// was chosen. Dismissing the dialog is never remembered.
+func (s *dialogState) showMessage(options MessageOptions, show func(MessageOptions) (string, bool, error)) (MessageResult, error) {
+	options, err := options.normalize()
+	if err != nil {
+		return MessageResult{}, err
+	}
+
+	if options.DontAskAgain != "" {
+		if button, ok := s.rememberedChoice(options.DontAskAgain); ok && options.hasButton(button) {
+			return MessageResult{
+				Button:       button,
+				Cancelled:    button == options.CancelButton,
+				DontAskAgain: true,
+				Remembered:   true,
+			}, nil
+		}
+	}
+
+	label, dontAskAgain, err := show(options)
+	if err != nil {
+		return MessageResult{}, err
+	}
+	button, clicked := options.resolve(label)
+	result := MessageResult{Button: button, Cancelled: !clicked || button == options.CancelButton}
+	if options.DontAskAgain != "" && dontAskAgain && clicked {
+		s.rememberChoice(options.DontAskAgain, button)
+		result.DontAskAgain = true
+	}
+	return result, nil
+}
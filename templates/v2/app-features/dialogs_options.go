@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DialogFilter is one entry of a dialog's file type list. Pattern holds one or
+// more globs separated by semicolons, e.g. "*.png;*.jpg".
+type DialogFilter struct {
+	DisplayName string `json:"displayName"`
+	Pattern     string `json:"pattern"`
+}
+
+// FileDialogOptions configures the open and save dialogs from the frontend.
+// Unset fields keep the platform defaults.
+type FileDialogOptions struct {
+	// Purpose is a key such as "import" or "export"; the dialog starts where it
+	// was last used for the same purpose, falling back to DefaultDirectory
+	Purpose string `json:"purpose,omitempty"`
+	Title   string `json:"title,omitempty"`
+	// DefaultDirectory is where the dialog starts; ignored if it does not exist
+	DefaultDirectory string `json:"defaultDirectory,omitempty"`
+	// DefaultFilename is the initial name in save dialogs and may not contain a path
+	DefaultFilename string         `json:"defaultFilename,omitempty"`
+	Filters         []DialogFilter `json:"filters,omitempty"`
+	ShowHiddenFiles bool           `json:"showHiddenFiles,omitempty"`
+	// CanCreateDirectories adds a "New Folder" button where the platform supports it
+	CanCreateDirectories bool `json:"canCreateDirectories,omitempty"`
+	// ResolvesAliases returns the targets of macOS aliases rather than the aliases
+	ResolvesAliases bool `json:"resolvesAliases,omitempty"`
+}
+
+// allFilesFilter is offered by the convenience dialogs
+var allFilesFilter = DialogFilter{DisplayName: "All Files (*.*)", Pattern: "*.*"}
+
+// normalize validates options coming from the frontend and returns a cleaned
+// copy: empty filters are dropped, filters without a name are named after
+// their pattern, and a default directory that no longer exists is cleared.
+func (o FileDialogOptions) normalize() (FileDialogOptions, error) {
+	if o.DefaultFilename != "" && filepath.Base(o.DefaultFilename) != o.DefaultFilename {
+		return o, fmt.Errorf("default filename %q must not contain a directory", o.DefaultFilename)
+	}
+
+	if o.DefaultDirectory != "" {
+		info, err := os.Stat(o.DefaultDirectory)
+		if err != nil || !info.IsDir() {
+			o.DefaultDirectory = ""
+		}
+	}
+
+	filters := make([]DialogFilter, 0, len(o.Filters))
+	for _, filter := range o.Filters {
+		var patterns []string
+		for _, pattern := range strings.Split(filter.Pattern, ";") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+		if len(patterns) == 0 {
+			continue
+		}
+		filter.Pattern = strings.Join(patterns, ";")
+		if filter.DisplayName == "" {
+			filter.DisplayName = filter.Pattern
+		}
+		filters = append(filters, filter)
+	}
+	o.Filters = filters
+	return o, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileDialogOptionsNormalize(t *testing.T) {
+	dir := t.TempDir()
+
+	options, err := FileDialogOptions{
+		DefaultDirectory: dir,
+		DefaultFilename:  "report.csv",
+		Filters: []DialogFilter{
+			{DisplayName: "Images", Pattern: "*.png; *.jpg ;"},
+			{Pattern: "*.csv"},
+			{DisplayName: "Empty", Pattern: " ; "},
+		},
+	}.normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if options.DefaultDirectory != dir {
+		t.Errorf("DefaultDirectory = %q, want %q", options.DefaultDirectory, dir)
+	}
+	want := []DialogFilter{
+		{DisplayName: "Images", Pattern: "*.png;*.jpg"},
+		{DisplayName: "*.csv", Pattern: "*.csv"},
+	}
+	if !reflect.DeepEqual(options.Filters, want) {
+		t.Errorf("Filters = %+v, want %+v", options.Filters, want)
+	}
+}
+
+func TestFileDialogOptionsNormalizeMissingDirectory(t *testing.T) {
+	options, err := FileDialogOptions{DefaultDirectory: filepath.Join(t.TempDir(), "gone")}.normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.DefaultDirectory != "" {
+		t.Errorf("DefaultDirectory = %q, want it cleared", options.DefaultDirectory)
+	}
+}
+
+func TestFileDialogOptionsNormalizeRejectsFilenamePath(t *testing.T) {
+	name := filepath.Join("..", "outside.txt")
+	if _, err := (FileDialogOptions{DefaultFilename: name}).normalize(); err == nil {
+		t.Errorf("normalize accepted default filename %q", name)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestDialogStateRemembersDirectoryPerPurpose(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(t.TempDir(), "dialogs.json")
+	imports := filepath.Join(dir, "imports")
+	exports := filepath.Join(dir, "exports")
+	os.Mkdir(imports, 0755)
+	os.Mkdir(exports, 0755)
+
+	state := newDialogState(statePath)
+	state.remember("import", []string{filepath.Join(imports, "data.csv")}, false, true)
+	state.remember("export", []string{exports}, true, false)
+
+	// A fresh instance reads the persisted directories
+	state = newDialogState(statePath)
+	for purpose, want := range map[string]string{"import": imports, "export": exports} {
+		options, err := state.prepare(FileDialogOptions{Purpose: purpose})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if options.DefaultDirectory != want {
+			t.Errorf("%s DefaultDirectory = %q, want %q", purpose, options.DefaultDirectory, want)
+		}
+	}
+
+	// The remembered directory wins over the first-use default
+	options, _ := state.prepare(FileDialogOptions{Purpose: "import", DefaultDirectory: exports})
+	if options.DefaultDirectory != imports {
+		t.Errorf("DefaultDirectory = %q, want the remembered %q", options.DefaultDirectory, imports)
+	}
+	options, _ = state.prepare(FileDialogOptions{Purpose: "other", DefaultDirectory: exports})
+	if options.DefaultDirectory != exports {
+		t.Errorf("DefaultDirectory = %q, want the default %q", options.DefaultDirectory, exports)
+	}
+}
+
+func TestDialogStateForgetsMissingDirectory(t *testing.T) {
+	gone := filepath.Join(t.TempDir(), "gone")
+	os.Mkdir(gone, 0755)
+
+	state := newDialogState(filepath.Join(t.TempDir(), "dialogs.json"))
+	state.remember("import", []string{gone}, true, false)
+	os.Remove(gone)
+
+	options, err := state.prepare(FileDialogOptions{Purpose: "import"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.DefaultDirectory != "" {
+		t.Errorf("DefaultDirectory = %q, want it cleared", options.DefaultDirectory)
+	}
+	if _, ok := state.data.Directories["import"]; ok {
+		t.Error("missing directory was not forgotten")
+	}
+}
+
+func TestDialogStateRejectsInvalidPurpose(t *testing.T) {
+	state := newDialogState("")
+	for _, purpose := range []string{"../config", "a b", string(make([]byte, 65))} {
+		if _, err := state.prepare(FileDialogOptions{Purpose: purpose}); err != ErrInvalidDialogPurpose {
+			t.Errorf("prepare(%q) error = %v, want ErrInvalidDialogPurpose", purpose, err)
+		}
+	}
+}
+
+func TestDialogStateRecentFiles(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i := 0; i < maxRecentFiles+2; i++ {
+		path := filepath.Join(dir, "file"+strconv.Itoa(i)+".txt")
+		os.WriteFile(path, []byte("x"), 0644)
+		files = append(files, path)
+	}
+
+	state := newDialogState(filepath.Join(t.TempDir(), "dialogs.json"))
+	for _, path := range files {
+		state.remember("", []string{path}, false, true)
+	}
+	// Reopening a file moves it to the front instead of duplicating it
+	state.remember("", []string{files[5]}, false, true)
+	// Save dialogs and cancelled dialogs do not add entries
+	state.remember("", []string{filepath.Join(dir, "saved.txt")}, false, false)
+	state.remember("", []string{""}, false, true)
+	os.Remove(files[maxRecentFiles])
+
+	want := []string{files[5], files[11]}
+	for i := maxRecentFiles - 1; i >= 2; i-- {
+		if i != 5 {
+			want = append(want, files[i])
+		}
+	}
+	if got := state.recentFiles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("recentFiles() = %v, want %v", got, want)
+	}
+
+	if err := state.clearRecentFiles(); err != nil {
+		t.Fatal(err)
+	}
+	if got := newDialogState(state.path).recentFiles(); len(got) != 0 {
+		t.Errorf("recentFiles() after clear = %v", got)
+	}
+}
@@ -0,0 +1,599 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// FileEventName is the Wails event carrying FileEvent payloads
+const FileEventName = "fs:event"
+
+// File event operations
+const (
+	FileOpCreate   = "create"
+	FileOpWrite    = "write"
+	FileOpRemove   = "remove"
+	FileOpRename   = "rename"
+	FileOpChmod    = "chmod"
+	FileOpOverflow = "overflow"
+)
+
+var (
+	// ErrWatchNotFound is returned when unwatching an unknown ID or path
+	ErrWatchNotFound = errors.New("watch not found")
+	// ErrWatcherClosed is returned after the watcher has shut down
+	ErrWatcherClosed = errors.New("file watcher is closed")
+	// ErrWatchLimit is returned when the system cannot add more watches
+	ErrWatchLimit = errors.New("file watch limit reached")
+)
+
+// FileEvent is emitted for every change under a watch, after bursts for the
+// same path are coalesced. An "overflow" op means events were dropped and the
+// frontend should rescan Path.
+type FileEvent struct {
+	WatchID string `json:"watchId"`
+	Path    string `json:"path"`
+	Op      string `json:"op"`
+}
+
+// WatchInfo describes an active watch
+type WatchInfo struct {
+	ID        string       `json:"id"`
+	Path      string       `json:"path"`
+	Recursive bool         `json:"recursive"`
+	Options   WatchOptions `json:"options"`
+	// Backend is "notify" or "poll"
+	Backend string `json:"backend"`
+	// Filesystem names the network or FUSE filesystem that made the watch poll
+	Filesystem string `json:"filesystem,omitempty"`
+}
+
+// FileWatcher watches files and directory trees using fsnotify, or by
+// polling where notifications are unavailable
+type FileWatcher struct {
+	mu       sync.Mutex
+	ctx      context.Context
+	notify   *fsnotify.Watcher
+	watches  map[string]*fileWatch
+	dirRefs  map[string]int
+	nextID   uint64
+	interval time.Duration
+	debounce *eventDebouncer
+	emit     func(name string, data interface{})
+	wake     chan struct{}
+	pollers  sync.WaitGroup
+	store    *watchStore
+	missing  []storedWatch
+	holding  bool
+	held     []FileEvent
+	heldFull bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// fileWatch is one WatchFile call; watches may share directories
+type fileWatch struct {
+	id        string
+	seq       uint64
+	root      string
+	isDir     bool
+	recursive bool
+	options   WatchOptions
+	dirs      map[string]bool
+	entries   map[string]bool // paths known to exist in the watched directories
+	ignores   map[string][]ignoreRule
+	polling   bool
+	fsType    string
+	stop      context.CancelFunc
+	snapshot  map[string]fileState
+	dirty     bool
+	rescan    bool
+}
+
+// NewFileWatcher starts a watcher that delivers fs:event and fs:batch messages
+// to emit until ctx is done or Close is called
+func NewFileWatcher(ctx context.Context, emit func(name string, data interface{})) (*FileWatcher, error) {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", explainWatchError(err))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &FileWatcher{
+		ctx:      ctx,
+		notify:   notify,
+		watches:  make(map[string]*fileWatch),
+		dirRefs:  make(map[string]int),
+		interval: fileWatcherOptions.PollInterval,
+		debounce: newEventDebouncer(fileWatcherOptions),
+		wake:     make(chan struct{}, 1),
+		emit:     emit,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go w.run(ctx, notify)
+	return w, nil
+}
+
+// Watch adds a file or directory and returns its watch ID. Directories are
+// watched recursively when options.Recursive is set, including subdirectories
+// created later; the remaining options filter directory watches. Paths on
+// network or FUSE filesystems, and watches with options.Poll, are polled.
+// Watching the same path with the same options again returns the existing ID.
+func (w *FileWatcher) Watch(path string, options WatchOptions) (string, error) {
+	watch, err := w.add(path, options, "", 0)
+	if err != nil {
+		return "", err
+	}
+	return watch.id, nil
+}
+
+// add creates a watch, reusing id and seq when restoring a persisted one
+func (w *FileWatcher) add(path string, options WatchOptions, id string, seq uint64) (*fileWatch, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	watch := &fileWatch{
+		root:      absPath,
+		isDir:     info.IsDir(),
+		recursive: options.Recursive && info.IsDir(),
+		options:   options,
+		dirs:      make(map[string]bool),
+		entries:   make(map[string]bool),
+		ignores:   make(map[string][]ignoreRule),
+	}
+	watch.polling, watch.fsType = usePolling(absPath, options)
+
+	w.mu.Lock()
+	persist := w.store != nil
+	w.mu.Unlock()
+
+	// The first scan of a network share can be slow, so it runs unlocked
+	var states map[string]fileState
+	if watch.polling || persist {
+		if states, err = watch.scan(); err != nil {
+			return nil, err
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.notify == nil {
+		return nil, ErrWatcherClosed
+	}
+	if id == "" {
+		for _, existing := range w.watches {
+			if existing.root == absPath && reflect.DeepEqual(existing.options, options) {
+				return existing, nil
+			}
+		}
+		w.nextID++
+		id, seq = fmt.Sprintf("watch-%d", w.nextID), w.nextID
+	}
+	watch.id, watch.seq = id, seq
+
+	if watch.polling {
+		w.startPolling(watch, states)
+	} else {
+		if watch.isDir {
+			err = w.addTree(watch, absPath, watch.limitEntries())
+		} else {
+			// Watch the parent so atomic saves that replace the file are seen
+			err = w.addDir(watch, filepath.Dir(absPath))
+		}
+		if err != nil {
+			w.releaseAll(watch)
+			return nil, err
+		}
+	}
+
+	w.watches[watch.id] = watch
+	if persist {
+		watch.snapshot = cloneFileStates(states)
+		watch.dirty = true
+		w.saveWatches()
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+	return watch, nil
+}
+
+// Unwatch removes a watch by ID, or every watch rooted at a path
+func (w *FileWatcher) Unwatch(idOrPath string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if watch, ok := w.watches[idOrPath]; ok {
+		w.remove(watch)
+		return nil
+	}
+
+	absPath, err := filepath.Abs(idOrPath)
+	if err != nil {
+		return ErrWatchNotFound
+	}
+	found := w.forgetMissing(idOrPath, absPath)
+	for _, watch := range w.watches {
+		if watch.root == absPath {
+			w.remove(watch)
+			found = true
+		}
+	}
+	if !found {
+		return ErrWatchNotFound
+	}
+	return nil
+}
+
+// List returns the active watches in creation order
+func (w *FileWatcher) List() []WatchInfo {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.listLocked()
+}
+
+// listLocked builds List's result; w.mu must be held
+func (w *FileWatcher) listLocked() []WatchInfo {
+	watches := make([]*fileWatch, 0, len(w.watches))
+	for _, watch := range w.watches {
+		watches = append(watches, watch)
+	}
+	sort.Slice(watches, func(i, j int) bool { return watches[i].seq < watches[j].seq })
+
+	infos := make([]WatchInfo, len(watches))
+	for i, watch := range watches {
+		infos[i] = WatchInfo{
+			ID:         watch.id,
+			Path:       watch.root,
+			Recursive:  watch.recursive,
+			Options:    watch.options,
+			Backend:    WatchBackendNotify,
+			Filesystem: watch.fsType,
+		}
+		if watch.polling {
+			infos[i].Backend = WatchBackendPoll
+		}
+	}
+	return infos
+}
+
+// Close stops the watcher, waits for its goroutines to exit and saves the
+// snapshots of persisted watches
+func (w *FileWatcher) Close() error {
+	w.cancel()
+	<-w.done
+	w.pollers.Wait()
+	w.saveSnapshots()
+	return nil
+}
+
+// remove drops a watch, its directories, poller and pending events
+func (w *FileWatcher) remove(watch *fileWatch) {
+	if watch.stop != nil {
+		watch.stop()
+	}
+	w.releaseAll(watch)
+	w.debounce.drop(watch.id)
+	delete(w.watches, watch.id)
+	w.forget(watch.id)
+}
+
+// addDir registers dir for watch, sharing the fsnotify watch with other watches
+func (w *FileWatcher) addDir(watch *fileWatch, dir string) error {
+	if watch.dirs[dir] {
+		return nil
+	}
+	// Listed first, so an entry created before the watch is in place is
+	// missed rather than taken for one that existed
+	watch.recordEntries(dir)
+	if w.dirRefs[dir] == 0 {
+		if err := w.notify.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", dir, explainWatchError(err))
+		}
+	}
+	w.dirRefs[dir]++
+	watch.dirs[dir] = true
+	return nil
+}
+
+// recordEntries remembers what exists in dir, so that a later create over one
+// of its entries, such as an atomic save, is reported as a write. File watches
+// only track their own path.
+func (watch *fileWatch) recordEntries(dir string) {
+	if !watch.isDir {
+		if _, err := os.Lstat(watch.root); err == nil {
+			watch.entries[watch.root] = true
+		}
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		watch.entries[filepath.Join(dir, entry.Name())] = true
+	}
+}
+
+// addTree registers start and the directories below it that pass the watch's
+// filters and depth limit, calling found for every entry that passes
+func (w *FileWatcher) addTree(watch *fileWatch, start string, found func(path string, entry fs.DirEntry) error) error {
+	return watch.walkTree(start, found, func(dir string) error {
+		return w.addDir(watch, dir)
+	})
+}
+
+// releaseDir drops watch's reference to dir, removing the fsnotify watch when unused
+func (w *FileWatcher) releaseDir(watch *fileWatch, dir string) {
+	if !watch.dirs[dir] {
+		return
+	}
+	delete(watch.dirs, dir)
+	w.dirRefs[dir]--
+	if w.dirRefs[dir] <= 0 {
+		delete(w.dirRefs, dir)
+		// Fails harmlessly if the kernel already dropped a deleted directory
+		w.notify.Remove(dir)
+	}
+}
+
+// releaseAll drops every directory held by watch
+func (w *FileWatcher) releaseAll(watch *fileWatch) {
+	for dir := range watch.dirs {
+		w.releaseDir(watch, dir)
+	}
+}
+
+// run forwards debounced fsnotify events until ctx is done. Pending events are
+// dropped on shutdown.
+func (w *FileWatcher) run(ctx context.Context, notify *fsnotify.Watcher) {
+	defer close(w.done)
+	defer func() {
+		w.mu.Lock()
+		notify.Close()
+		w.notify = nil
+		w.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	saveTimer := time.NewTimer(time.Hour)
+	saveTimer.Stop()
+	defer saveTimer.Stop()
+	saveArmed := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-notify.Events:
+			if !ok {
+				return
+			}
+			w.handle(event)
+		case err, ok := <-notify.Errors:
+			if !ok {
+				return
+			}
+			w.handleError(err)
+		case <-w.wake:
+		case <-timer.C:
+		case <-saveTimer.C:
+			saveArmed = false
+			w.saveSnapshots()
+		}
+		w.flush(timer)
+
+		if !saveArmed && w.dirtySnapshots() {
+			saveTimer.Reset(snapshotSaveDelay)
+			saveArmed = true
+		}
+	}
+}
+
+// flush sends the events that are due and rearms timer for the next ones
+func (w *FileWatcher) flush(timer *time.Timer) {
+	now := time.Now()
+	w.mu.Lock()
+	events := w.debounce.flush(now)
+	next, pending := w.debounce.next()
+	w.track(events)
+	w.mu.Unlock()
+
+	w.send(events)
+
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	if pending {
+		timer.Reset(next.Sub(now))
+	}
+}
+
+// send emits events individually, or as one fs:batch message when there are
+// many, unless they are held until Ready
+func (w *FileWatcher) send(events []FileEvent) {
+	if w.hold(events) {
+		return
+	}
+	if size := w.debounce.options.BatchSize; size > 0 && len(events) >= size {
+		w.emit(FileBatchEventName, events)
+		return
+	}
+	for _, event := range events {
+		w.emit(FileEventName, event)
+	}
+}
+
+// handle queues one fsnotify event for every matching watch
+func (w *FileWatcher) handle(event fsnotify.Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	type change struct {
+		event FileEvent
+		known bool
+	}
+	var changes []change
+	op := fileOp(event.Op)
+	info, err := os.Lstat(event.Name)
+	statDir := err == nil && info.IsDir()
+
+	for _, watch := range w.watches {
+		if watch.polling {
+			continue
+		}
+		isDir := statDir || watch.dirs[event.Name]
+		if !watch.matches(event.Name) || !watch.allows(event.Name, isDir) {
+			continue
+		}
+		if isIgnoreFile(event.Name) && watch.dirs[filepath.Dir(event.Name)] {
+			// New rules apply to later events; existing directory watches are kept
+			watch.loadIgnores(filepath.Dir(event.Name))
+		}
+		known := watch.entries[event.Name]
+		changes = append(changes, change{FileEvent{WatchID: watch.id, Path: event.Name, Op: op}, known})
+
+		if event.Has(fsnotify.Create) {
+			watch.entries[event.Name] = true
+		}
+		if watch.recursive && isDir && event.Has(fsnotify.Create) {
+			for _, created := range w.addCreatedTree(watch, event.Name) {
+				changes = append(changes, change{created, false})
+			}
+		}
+		if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+			watch.forgetEntries(event.Name, isDir)
+			for dir := range watch.dirs {
+				if dir == event.Name || isWithin(dir, event.Name) {
+					w.releaseDir(watch, dir)
+				}
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, c := range changes {
+		w.debounce.add(c.event, c.known, now)
+	}
+}
+
+// forgetEntries drops a removed path, and everything below it if it was a directory
+func (watch *fileWatch) forgetEntries(path string, isDir bool) {
+	delete(watch.entries, path)
+	if !isDir {
+		return
+	}
+	for entry := range watch.entries {
+		if isWithin(entry, path) {
+			delete(watch.entries, entry)
+		}
+	}
+}
+
+// addCreatedTree watches a newly created directory and reports entries that
+// appeared in it before the watch was in place
+func (w *FileWatcher) addCreatedTree(watch *fileWatch, path string) []FileEvent {
+	var events []FileEvent
+	err := w.addTree(watch, path, func(entryPath string, _ fs.DirEntry) error {
+		events = append(events, FileEvent{WatchID: watch.id, Path: entryPath, Op: FileOpCreate})
+		return nil
+	})
+	if err != nil {
+		w.logError(err)
+	}
+	return events
+}
+
+// handleError reports dropped events as an overflow so the frontend can
+// rescan, after sending whatever was still pending
+func (w *FileWatcher) handleError(err error) {
+	if !errors.Is(err, fsnotify.ErrEventOverflow) {
+		w.logError(err)
+		return
+	}
+
+	w.mu.Lock()
+	pending := w.debounce.flushAll()
+	var overflows []FileEvent
+	for _, watch := range w.watches {
+		overflows = append(overflows, FileEvent{WatchID: watch.id, Path: watch.root, Op: FileOpOverflow})
+	}
+	w.track(pending)
+	w.track(overflows)
+	w.mu.Unlock()
+
+	w.send(pending)
+	if w.hold(overflows) {
+		return
+	}
+	for _, e := range overflows {
+		w.emit(FileEventName, e)
+	}
+}
+
+// logError prints watcher errors that have no better destination
+func (w *FileWatcher) logError(err error) {
+	fmt.Println("file watcher:", err)
+}
+
+// matches reports whether path belongs to the watch
+func (watch *fileWatch) matches(path string) bool {
+	switch {
+	case !watch.isDir:
+		return path == watch.root
+	case watch.recursive:
+		return path == watch.root || isWithin(path, watch.root)
+	default:
+		return path == watch.root || filepath.Dir(path) == watch.root
+	}
+}
+
+// isWithin reports whether path is strictly below dir
+func isWithin(path, dir string) bool {
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// fileOp names the most significant operation in op
+func fileOp(op fsnotify.Op) string {
+	switch {
+	case op.Has(fsnotify.Create):
+		return FileOpCreate
+	case op.Has(fsnotify.Remove):
+		return FileOpRemove
+	case op.Has(fsnotify.Rename):
+		return FileOpRename
+	case op.Has(fsnotify.Write):
+		return FileOpWrite
+	default:
+		return FileOpChmod
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// FileBatchEventName is the Wails event carrying a []FileEvent when many paths
+// change at once, e.g. during a git checkout
+const FileBatchEventName = "fs:batch"
+
+// FileWatcherOptions configures how raw filesystem events are debounced, how
+// often polling watches scan and whether watches persist
+type FileWatcherOptions struct {
+	// Debounce is how long a path must be quiet before its coalesced event is
+	// sent; zero sends every event as it arrives
+	Debounce time.Duration
+	// MaxDelay bounds how long a path that keeps changing is held back
+	MaxDelay time.Duration
+	// BatchSize is the number of pending changes at which they are held until
+	// the whole watcher is quiet and then sent as one fs:batch message
+	BatchSize int
+	// PollInterval is the default scan period for polling watches
+	PollInterval time.Duration
+	// Persist saves the watch set and snapshots so watches are restored, and
+	// offline changes reported, on the next launch
+	Persist bool
+}
+
+// fileWatcherOptions can be adjusted before the first watch is added
+var fileWatcherOptions = FileWatcherOptions{
+	Debounce:     100 * time.Millisecond,
+	MaxDelay:     time.Second,
+	BatchSize:    20,
+	PollInterval: 2 * time.Second,
+	Persist:      true,
+}
+
+// pendingEvent accumulates the raw events for one path of one watch
+type pendingEvent struct {
+	seq     uint64
+	watchID string
+	path    string
+	existed bool // the path existed before the first event
+	exists  bool // the path exists after the latest event
+	changed bool // content or presence changed, not just metadata
+	renamed bool // the latest removal was a rename
+	first   time.Time
+	last    time.Time
+}
+
+// add folds one raw op into the pending state. known reports whether a created
+// path was already present, as when an atomic save renames a file over it.
+func (p *pendingEvent) add(op string, known bool, now time.Time) {
+	isNew := p.first.IsZero()
+	if isNew {
+		p.first = now
+	}
+	p.last = now
+
+	switch op {
+	case FileOpCreate:
+		if isNew {
+			p.existed = known
+		}
+		p.exists, p.changed = true, true
+	case FileOpRemove, FileOpRename:
+		if isNew {
+			p.existed = true
+		}
+		p.exists, p.changed = false, true
+		p.renamed = op == FileOpRename
+	case FileOpWrite:
+		if isNew {
+			p.existed = true
+		}
+		p.exists, p.changed = true, true
+	default:
+		if isNew {
+			p.existed, p.exists = true, true
+		}
+	}
+}
+
+// result returns the single logical event for the accumulated ops, or false
+// when nothing observable happened (a temporary file created and removed)
+func (p *pendingEvent) result() (FileEvent, bool) {
+	event := FileEvent{WatchID: p.watchID, Path: p.path}
+	switch {
+	case !p.existed && !p.exists:
+		return event, false
+	case !p.existed:
+		event.Op = FileOpCreate
+	case !p.exists && p.renamed:
+		event.Op = FileOpRename
+	case !p.exists:
+		event.Op = FileOpRemove
+	case p.changed:
+		// Includes atomic saves, where the file is replaced by a rename
+		event.Op = FileOpWrite
+	default:
+		event.Op = FileOpChmod
+	}
+	return event, true
+}
+
+// due is when the pending event may be sent
+func (p *pendingEvent) due(options FileWatcherOptions) time.Time {
+	due := p.last.Add(options.Debounce)
+	if limit := p.first.Add(options.MaxDelay); options.MaxDelay > 0 && limit.Before(due) {
+		return limit
+	}
+	return due
+}
+
+// eventDebouncer coalesces raw events per watch and path. It is not safe for
+// concurrent use; FileWatcher guards it with its mutex.
+type eventDebouncer struct {
+	options FileWatcherOptions
+	pending map[string]*pendingEvent
+	seq     uint64
+	first   time.Time
+	last    time.Time
+}
+
+func newEventDebouncer(options FileWatcherOptions) *eventDebouncer {
+	return &eventDebouncer{options: options, pending: make(map[string]*pendingEvent)}
+}
+
+// add records a raw event
+func (d *eventDebouncer) add(event FileEvent, known bool, now time.Time) {
+	if len(d.pending) == 0 {
+		d.first = now
+	}
+	d.last = now
+
+	key := event.WatchID + "\x00" + event.Path
+	p, ok := d.pending[key]
+	if !ok {
+		d.seq++
+		p = &pendingEvent{seq: d.seq, watchID: event.WatchID, path: event.Path}
+		d.pending[key] = p
+	}
+	p.add(event.Op, known, now)
+}
+
+// batching reports whether enough changes are pending to send them together
+func (d *eventDebouncer) batching() bool {
+	return d.options.BatchSize > 0 && len(d.pending) >= d.options.BatchSize
+}
+
+// next returns when flush should run, or false if nothing is pending
+func (d *eventDebouncer) next() (time.Time, bool) {
+	if len(d.pending) == 0 {
+		return time.Time{}, false
+	}
+
+	if d.batching() {
+		due := d.last.Add(d.options.Debounce)
+		if limit := d.first.Add(d.options.MaxDelay); d.options.MaxDelay > 0 && limit.Before(due) {
+			return limit, true
+		}
+		return due, true
+	}
+
+	var next time.Time
+	for _, p := range d.pending {
+		if due := p.due(d.options); next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	return next, true
+}
+
+// flush removes and returns the events that are due, in arrival order. In
+// batch mode everything is sent together once the watcher has been quiet.
+func (d *eventDebouncer) flush(now time.Time) []FileEvent {
+	var ready []*pendingEvent
+	if d.batching() {
+		if due, _ := d.next(); now.Before(due) {
+			return nil
+		}
+		for key, p := range d.pending {
+			ready = append(ready, p)
+			delete(d.pending, key)
+		}
+	} else {
+		for key, p := range d.pending {
+			if !now.Before(p.due(d.options)) {
+				ready = append(ready, p)
+				delete(d.pending, key)
+			}
+		}
+	}
+	return pendingResults(ready)
+}
+
+// flushAll removes and returns every pending event
+func (d *eventDebouncer) flushAll() []FileEvent {
+	ready := make([]*pendingEvent, 0, len(d.pending))
+	for key, p := range d.pending {
+		ready = append(ready, p)
+		delete(d.pending, key)
+	}
+	return pendingResults(ready)
+}
+
+// drop forgets pending events for a watch that was removed
+func (d *eventDebouncer) drop(watchID string) {
+	for key, p := range d.pending {
+		if p.watchID == watchID {
+			delete(d.pending, key)
+		}
+	}
+}
+
+// pendingResults converts pending entries to events in arrival order
+func pendingResults(ready []*pendingEvent) []FileEvent {
+	sort.Slice(ready, func(i, j int) bool { return ready[i].seq < ready[j].seq })
+
+	events := make([]FileEvent, 0, len(ready))
+	for _, p := range ready {
+		if event, ok := p.result(); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testDebounceOptions = FileWatcherOptions{
+	Debounce:  100 * time.Millisecond,
+	MaxDelay:  time.Second,
+	BatchSize: 10,
+}
+
+func TestPendingEventCoalescing(t *testing.T) {
+	tests := []struct {
+		name  string
+		known bool
+		ops   []string
+		want  string
+	}{
+		{"atomic save", true, []string{FileOpRename, FileOpCreate, FileOpWrite}, FileOpWrite},
+		{"replace by rename", true, []string{FileOpCreate}, FileOpWrite},
+		{"editor save", true, []string{FileOpWrite, FileOpChmod, FileOpWrite}, FileOpWrite},
+		{"new file", false, []string{FileOpCreate, FileOpWrite, FileOpChmod}, FileOpCreate},
+		{"metadata only", true, []string{FileOpChmod, FileOpChmod}, FileOpChmod},
+		{"deleted", true, []string{FileOpWrite, FileOpRemove}, FileOpRemove},
+		{"moved away", true, []string{FileOpRename}, FileOpRename},
+		{"temporary file", false, []string{FileOpCreate, FileOpWrite, FileOpRemove}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &pendingEvent{watchID: "watch-1", path: "/a"}
+			for _, op := range tt.ops {
+				p.add(op, tt.known, time.Now())
+			}
+			event, ok := p.result()
+			if tt.want == "" {
+				if ok {
+					t.Errorf("result = %+v, want nothing", event)
+				}
+				return
+			}
+			if !ok || event.Op != tt.want {
+				t.Errorf("result = %+v, %v, want %s", event, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventDebouncerWaitsForQuietPath(t *testing.T) {
+	d := newEventDebouncer(testDebounceOptions)
+	start := time.Now()
+
+	d.add(FileEvent{WatchID: "watch-1", Path: "/a", Op: FileOpWrite}, false, start)
+	d.add(FileEvent{WatchID: "watch-1", Path: "/a", Op: FileOpChmod}, false, start.Add(50*time.Millisecond))
+
+	if events := d.flush(start.Add(120 * time.Millisecond)); len(events) != 0 {
+		t.Fatalf("flush before quiet = %+v, want none", events)
+	}
+	if next, _ := d.next(); !next.Equal(start.Add(150 * time.Millisecond)) {
+		t.Errorf("next = %v, want 150ms after start", next.Sub(start))
+	}
+	events := d.flush(start.Add(150 * time.Millisecond))
+	if len(events) != 1 || events[0].Op != FileOpWrite {
+		t.Errorf("flush = %+v, want one write", events)
+	}
+	if _, pending := d.next(); pending {
+		t.Error("events still pending after flush")
+	}
+}
+
+func TestEventDebouncerMaxDelay(t *testing.T) {
+	d := newEventDebouncer(testDebounceOptions)
+	start := time.Now()
+
+	// A log file written every 50ms is never quiet
+	var flushed []FileEvent
+	for elapsed := time.Duration(0); elapsed <= 1500*time.Millisecond; elapsed += 50 * time.Millisecond {
+		now := start.Add(elapsed)
+		d.add(FileEvent{WatchID: "watch-1", Path: "/log", Op: FileOpWrite}, false, now)
+		flushed = append(flushed, d.flush(now)...)
+	}
+	if len(flushed) != 1 {
+		t.Errorf("flushed = %+v, want one event after MaxDelay", flushed)
+	}
+}
+
+func TestEventDebouncerBatchesTreeChanges(t *testing.T) {
+	d := newEventDebouncer(testDebounceOptions)
+	start := time.Now()
+
+	for i := 0; i < 15; i++ {
+		now := start.Add(time.Duration(i) * 5 * time.Millisecond)
+		d.add(FileEvent{WatchID: "watch-1", Path: fmt.Sprintf("/tree/%02d", i), Op: FileOpCreate}, false, now)
+		if events := d.flush(now); len(events) != 0 {
+			t.Fatalf("flushed %+v during the burst", events)
+		}
+	}
+
+	// The first paths are individually quiet, but the batch waits for the last one
+	last := start.Add(14 * 5 * time.Millisecond)
+	if events := d.flush(last.Add(50 * time.Millisecond)); len(events) != 0 {
+		t.Fatalf("flushed %d events before the tree was quiet", len(events))
+	}
+	events := d.flush(last.Add(100 * time.Millisecond))
+	if len(events) != 15 {
+		t.Fatalf("batch = %d events, want 15", len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i-1].Path > events[i].Path {
+			t.Fatalf("batch out of arrival order: %s before %s", events[i-1].Path, events[i].Path)
+		}
+	}
+}
+
+func TestFileWatcherSendsBatch(t *testing.T) {
+	root := t.TempDir()
+	batches := make(chan []FileEvent, 10)
+	w, err := NewFileWatcher(context.Background(), func(name string, data interface{}) {
+		if name == FileBatchEventName {
+			batches <- data.([]FileEvent)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if _, err := w.Watch(root, WatchOptions{Recursive: true}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2*fileWatcherOptions.BatchSize; i++ {
+		os.WriteFile(filepath.Join(root, fmt.Sprintf("file-%02d.txt", i)), []byte("x"), 0644)
+	}
+
+	select {
+	case batch := <-batches:
+		if len(batch) != 2*fileWatcherOptions.BatchSize {
+			t.Errorf("batch = %d events, want %d", len(batch), 2*fileWatcherOptions.BatchSize)
+		}
+		for _, event := range batch {
+			if event.Op != FileOpCreate {
+				t.Errorf("event = %+v, want create", event)
+			}
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no fs:batch message")
+	}
+}
@@ -0,0 +1,34 @@
+//go:build darwin
+
+package main
+
+import (
+	"strings"
+	"syscall"
+)
+
+// remoteFilesystemNames are statfs type names of filesystems where kqueue
+// misses changes made by other machines
+var remoteFilesystemNames = []string{"nfs", "smbfs", "afpfs", "webdav", "macfuse", "osxfuse", "fusefs"}
+
+// remoteFilesystem reports whether path is on a filesystem that needs polling
+func remoteFilesystem(path string) (string, bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return "", false
+	}
+
+	var name strings.Builder
+	for _, c := range stat.Fstypename {
+		if c == 0 {
+			break
+		}
+		name.WriteByte(byte(c))
+	}
+	for _, remote := range remoteFilesystemNames {
+		if name.String() == remote {
+			return remote, true
+		}
+	}
+	return "", false
+}
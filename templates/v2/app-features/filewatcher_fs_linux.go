@@ -0,0 +1,28 @@
+//go:build linux
+
+package main
+
+import "syscall"
+
+// remoteFilesystemTypes are statfs magic numbers of filesystems where inotify
+// misses changes made by other machines or by the FUSE daemon
+var remoteFilesystemTypes = map[uint32]string{
+	0x6969:     "nfs",
+	0x517b:     "smb",
+	0xff534d42: "cifs",
+	0xfe534d42: "smb2",
+	0x65735546: "fuse",
+	0x01021997: "9p",
+	0x5346414f: "afs",
+	0x00c36400: "ceph",
+}
+
+// remoteFilesystem reports whether path is on a filesystem that needs polling
+func remoteFilesystem(path string) (string, bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return "", false
+	}
+	name, ok := remoteFilesystemTypes[uint32(stat.Type)]
+	return name, ok
+}
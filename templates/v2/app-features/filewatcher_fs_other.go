@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package main
+
+// remoteFilesystem never forces polling; ReadDirectoryChangesW also reports
+// changes on SMB shares
+func remoteFilesystem(path string) (string, bool) {
+	return "", false
+}
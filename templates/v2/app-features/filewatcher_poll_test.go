@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiffFileStates(t *testing.T) {
+	then := time.Now()
+	later := then.Add(time.Second)
+	previous := map[string]fileState{
+		"/w/dir":     {isDir: true, mode: os.ModeDir | 0755},
+		"/w/same":    {size: 1, modTime: then, mode: 0644},
+		"/w/grown":   {size: 1, modTime: then, mode: 0644},
+		"/w/touched": {size: 1, modTime: then, mode: 0644},
+		"/w/hashed":  {size: 1, modTime: then, mode: 0644, hash: [32]byte{1}, hashed: true},
+		"/w/rehash":  {size: 1, modTime: then, mode: 0644, hash: [32]byte{1}, hashed: true},
+		"/w/private": {size: 1, modTime: then, mode: 0644},
+		"/w/gone":    {size: 1, modTime: then, mode: 0644},
+	}
+	current := map[string]fileState{
+		"/w/dir":     {isDir: true, mode: os.ModeDir | 0755},
+		"/w/same":    {size: 1, modTime: then, mode: 0644},
+		"/w/grown":   {size: 2, modTime: later, mode: 0644},
+		"/w/touched": {size: 1, modTime: later, mode: 0644},
+		"/w/hashed":  {size: 1, modTime: later, mode: 0644, hash: [32]byte{1}, hashed: true},
+		"/w/rehash":  {size: 1, modTime: then, mode: 0644, hash: [32]byte{2}, hashed: true},
+		"/w/private": {size: 1, modTime: then, mode: 0600},
+		"/w/new":     {size: 1, modTime: later, mode: 0644},
+	}
+
+	want := map[string]string{
+		"/w/grown":   FileOpWrite,
+		"/w/touched": FileOpWrite,
+		"/w/hashed":  FileOpChmod,
+		"/w/rehash":  FileOpWrite,
+		"/w/private": FileOpChmod,
+		"/w/new":     FileOpCreate,
+		"/w/gone":    FileOpRemove,
+	}
+	events := diffFileStates("watch-1", previous, current)
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v, want %d", events, len(want))
+	}
+	for _, event := range events {
+		if want[event.Path] != event.Op || event.WatchID != "watch-1" {
+			t.Errorf("event %+v, want op %q", event, want[event.Path])
+		}
+	}
+}
+
+func TestFileWatcherPolling(t *testing.T) {
+	saved := fileWatcherOptions
+	fileWatcherOptions.PollInterval = 20 * time.Millisecond
+	defer func() { fileWatcherOptions = saved }()
+
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{"notes.txt": "aaaa"})
+	w, events := newTestFileWatcher(t)
+
+	id, err := w.Watch(root, WatchOptions{Recursive: true, Poll: true, HashContent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := w.List(); len(info) != 1 || info[0].Backend != WatchBackendPoll {
+		t.Fatalf("List() = %+v, want one polling watch", info)
+	}
+
+	created := filepath.Join(root, "sub", "new.txt")
+	makeTree(t, root, map[string]string{"sub/new.txt": "x"})
+	if event := waitForFileEvent(t, events, created); event.Op != FileOpCreate || event.WatchID != id {
+		t.Errorf("event = %+v, want create from %s", event, id)
+	}
+
+	// Same size and modification time, different content
+	notes := filepath.Join(root, "notes.txt")
+	info, _ := os.Stat(notes)
+	os.WriteFile(notes, []byte("bbbb"), 0644)
+	os.Chtimes(notes, info.ModTime(), info.ModTime())
+	if event := waitForFileEvent(t, events, notes); event.Op != FileOpWrite {
+		t.Errorf("event = %+v, want write", event)
+	}
+
+	if err := w.Unwatch(id); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(notes)
+	expectNoFileEvent(t, events)
+}
+
+func TestFileWatcherPollingFile(t *testing.T) {
+	saved := fileWatcherOptions
+	fileWatcherOptions.PollInterval = 20 * time.Millisecond
+	defer func() { fileWatcherOptions = saved }()
+
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{"config.json": "{}"})
+	target := filepath.Join(root, "config.json")
+	w, events := newTestFileWatcher(t)
+
+	if _, err := w.Watch(target, WatchOptions{Poll: true}); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(target)
+	if event := waitForFileEvent(t, events, target); event.Op != FileOpRemove {
+		t.Errorf("event = %+v, want remove", event)
+	}
+}
+
+func TestFileWatcherCloseStopsPollers(t *testing.T) {
+	w, err := NewFileWatcher(context.Background(), func(string, interface{}) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Watch(t.TempDir(), WatchOptions{Recursive: true, Poll: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		w.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not wait for the poller to stop")
+	}
+}
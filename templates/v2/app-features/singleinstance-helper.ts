@@ -0,0 +1,20 @@
+// Single Instance Helper
+import { EventsOn } from '../wailsjs/runtime/runtime'
+
+interface SecondInstanceMessage {
+  args: string[]
+  workingDir: string
+}
+
+// Called whenever the app is launched again while already running.
+// The window has already been restored and focused by the backend.
+export function onSecondInstance(listener: (message: SecondInstanceMessage) => void) {
+  return EventsOn('second-instance', listener)
+}
+
+// Example usage
+export function setupSecondInstance() {
+  onSecondInstance(({ args, workingDir }) => {
+    console.log('Launched again with:', args, 'from', workingDir)
+  })
+}
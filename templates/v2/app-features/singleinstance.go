@@ -1,51 +1,289 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"path/filepath"
+	"runtime"
+	"time"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// SecondInstanceMessage is sent by a second launch to the running instance
+type SecondInstanceMessage struct {
+	Args       []string `json:"args"`
+	WorkingDir string   `json:"workingDir"`
+}
+
+// secondInstanceHooks are called on the running instance for every forwarded launch
+var secondInstanceHooks []func(a *App, msg SecondInstanceMessage)
+
 // lockFile represents the lock file path
 var lockFile string
 
+// lockHandle keeps the lock file open and locked for the lifetime of the process.
+// The OS releases the lock when the process exits, including after a crash.
+var lockHandle *os.File
+
+// instanceLock is the owner record stored in the lock file
+type instanceLock struct {
+	PID int    `json:"pid"`
+	Exe string `json:"exe"`
+}
+
+// errLocked is returned by tryLockFile when another process holds the lock
+var errLocked = errors.New("lock is held by another process")
+
+// instanceListener receives messages from second instances
+var instanceListener net.Listener
+
+// SecondInstanceEvent carries a second launch's arguments to the frontend
+const SecondInstanceEvent = "second-instance"
+
+// maxInstanceMessageSize limits what a second instance may send
+const maxInstanceMessageSize = 64 * 1024
+
 // initSingleInstance initializes the single instance lock
 func (a *App) initSingleInstance() error {
-	// Get user's temp directory
-	tmpDir := os.TempDir()
-	lockFile = filepath.Join(tmpDir, "{{PROJECT_NAME}}.lock")
-
-	// Try to create lock file atomically
-	pid := fmt.Sprintf("%d", os.Getpid())
-	file, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
-	if err != nil {
-		if os.IsExist(err) {
-			// Lock file already exists, read the PID to show which process is running
-			data, readErr := os.ReadFile(lockFile)
-			if readErr == nil {
-				fmt.Printf("Another instance is already running (PID: %s)\n", string(data))
-			} else {
-				fmt.Printf("Another instance is already running\n")
-			}
-			return fmt.Errorf("application is already running")
+	acquired, err := acquireInstanceLock()
+	if err != nil {
+		return err
+	}
+
+	if !acquired {
+		// Hand our arguments to the running instance and exit quietly
+		if forwardErr := forwardToRunningInstance(instanceSocketPath()); forwardErr == nil {
+			fmt.Println("Forwarded launch to the running instance")
+			os.Exit(0)
+		}
+
+		// Read the PID to show which process is running
+		if owner, readErr := readInstanceLock(lockFile); readErr == nil {
+			fmt.Printf("Another instance is already running (PID: %d)\n", owner.PID)
+		} else {
+			fmt.Printf("Another instance is already running\n")
 		}
-		return fmt.Errorf("failed to create lock file: %w", err)
+		return fmt.Errorf("application is already running")
 	}
-	defer file.Close()
 
-	// Write current PID to the lock file
-	_, err = file.WriteString(pid)
-	if err != nil {
-		os.Remove(lockFile) // Clean up if write fails
-		return fmt.Errorf("failed to write PID to lock file: %w", err)
+	if err := a.listenForSecondInstances(instanceSocketPath()); err != nil {
+		fmt.Printf("Second instance forwarding disabled: %v\n", err)
 	}
 
 	return nil
 }
 
-// releaseSingleInstance removes the lock file
+// releaseSingleInstance stops listening and releases the lock
 func (a *App) releaseSingleInstance() {
-	if lockFile != "" {
-		os.Remove(lockFile)
+	if instanceListener != nil {
+		instanceListener.Close()
+		os.Remove(instanceSocketPath())
+	}
+
+	// The lock file itself is left in place: deleting it could let a new
+	// instance lock a fresh file while another still holds the old one
+	if lockHandle != nil {
+		unlockFile(lockHandle)
+		lockHandle.Close()
+		lockHandle = nil
+	}
+}
+
+// acquireInstanceLock takes the per-user, per-session lock without blocking.
+// It reports false when another instance holds it.
+func acquireInstanceLock() (bool, error) {
+	dir, err := instanceRuntimeDir()
+	if err != nil {
+		return false, fmt.Errorf("failed to create runtime directory: %w", err)
+	}
+	lockFile = filepath.Join(dir, instanceName()+".lock")
+
+	file, err := lockInstanceFile(lockFile)
+	if errors.Is(err, errLocked) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock %s: %w", lockFile, err)
+	}
+
+	// Record who owns the lock for diagnostics
+	exe, _ := os.Executable()
+	file.Truncate(0)
+	json.NewEncoder(file).Encode(instanceLock{PID: os.Getpid(), Exe: exe})
+
+	lockHandle = file
+	return true, nil
+}
+
+// lockInstanceFile opens the lock file at path and locks it without blocking.
+// It returns errLocked when another open handle holds the lock.
+func lockInstanceFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := tryLockFile(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// readInstanceLock parses the owner record of a lock file
+func readInstanceLock(path string) (instanceLock, error) {
+	var lock instanceLock
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return lock, err
+	}
+	if err := json.Unmarshal(data, &lock); err != nil || lock.PID <= 0 {
+		return lock, fmt.Errorf("invalid lock file %s", path)
+	}
+	return lock, nil
+}
+
+// instanceRuntimeDir returns a private per-user directory for the lock and socket
+func instanceRuntimeDir() (string, error) {
+	var dir string
+	switch {
+	case runtime.GOOS == "linux" && os.Getenv("XDG_RUNTIME_DIR") != "":
+		dir = filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "{{PROJECT_NAME}}")
+	case runtime.GOOS == "windows":
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(cacheDir, "{{PROJECT_NAME}}")
+	default:
+		// macOS sets a per-user TMPDIR; elsewhere the UID keeps users apart
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("{{PROJECT_NAME}}-%d", os.Getuid()))
 	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	// The directory may predate this launch, e.g. created by another user in /tmp
+	if err := checkPrivateDir(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// instanceName scopes the lock and socket to the current login session
+func instanceName() string {
+	if session := instanceSessionID(); session != "" {
+		return "instance-" + session
+	}
+	return "instance"
+}
+
+// instanceSocketPath returns the Unix domain socket used for IPC.
+// Windows 10 and later support Unix domain sockets natively.
+func instanceSocketPath() string {
+	dir, _ := instanceRuntimeDir()
+	return filepath.Join(dir, instanceName()+".sock")
+}
+
+// listenForSecondInstances accepts messages from later launches on socketPath
+func (a *App) listenForSecondInstances(socketPath string) error {
+	// We hold the lock, so any existing socket is left over from a crash
+	os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	os.Chmod(socketPath, 0600)
+	instanceListener = listener
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return // listener closed
+			}
+			go a.handleSecondInstance(conn)
+		}
+	}()
+
+	return nil
+}
+
+// handleSecondInstance reads one message and dispatches it to the hooks
+func (a *App) handleSecondInstance(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var msg SecondInstanceMessage
+	if err := json.NewDecoder(io.LimitReader(conn, maxInstanceMessageSize)).Decode(&msg); err != nil {
+		fmt.Printf("Invalid message from second instance: %v\n", err)
+		return
+	}
+	conn.Write([]byte("ok\n"))
+
+	if a.ctx != nil {
+		a.activateWindow()
+		wailsruntime.EventsEmit(a.ctx, SecondInstanceEvent, msg)
+	}
+
+	for _, hook := range secondInstanceHooks {
+		hook(a, msg)
+	}
+}
+
+// activateWindow restores, raises and focuses the main window so a second
+// launch is visible to the user instead of appearing to do nothing
+func (a *App) activateWindow() {
+	wailsruntime.WindowUnminimise(a.ctx)
+	wailsruntime.WindowShow(a.ctx)
+
+	// Window managers often refuse to hand focus to a background app;
+	// briefly pinning the window on top raises it anyway
+	wailsruntime.WindowSetAlwaysOnTop(a.ctx, true)
+	wailsruntime.WindowSetAlwaysOnTop(a.ctx, false)
+}
+
+// forwardToRunningInstance sends this process's arguments to the running
+// instance listening on socketPath
+func forwardToRunningInstance(socketPath string) error {
+	// The running instance may have just taken the lock and not be listening yet
+	var conn net.Conn
+	var err error
+	for attempt := 0; attempt < 5; attempt++ {
+		conn, err = net.DialTimeout("unix", socketPath, 2*time.Second)
+		if err == nil {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	workingDir, _ := os.Getwd()
+	msg := SecondInstanceMessage{
+		Args:       os.Args[1:],
+		WorkingDir: workingDir,
+	}
+	if err := json.NewEncoder(conn).Encode(msg); err != nil {
+		return err
+	}
+
+	// Wait for the acknowledgement so the message is not lost when we exit
+	ack, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if ack != "ok\n" {
+		return fmt.Errorf("unexpected reply from running instance: %q", ack)
+	}
+	return nil
 }
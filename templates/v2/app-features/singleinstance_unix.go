@@ -0,0 +1,54 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// tryLockFile takes a non-blocking exclusive flock on the file
+func tryLockFile(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLocked
+	}
+	return err
+}
+
+// unlockFile releases a lock taken by tryLockFile
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
+
+// instanceSessionID identifies the current login session, if known
+func instanceSessionID() string {
+	// systemd-logind on Linux, the security session on macOS
+	for _, key := range []string{"XDG_SESSION_ID", "SECURITYSESSIONID"} {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// checkPrivateDir refuses a runtime directory that another user could use to
+// capture the socket: it must be a real directory owned by us with mode 0700
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); !ok || int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is not owned by the current user", dir)
+	}
+	if info.Mode().Perm() != 0700 {
+		return fmt.Errorf("%s has mode %o, want 700", dir, info.Mode().Perm())
+	}
+	return nil
+}
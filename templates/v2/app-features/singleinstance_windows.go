@@ -0,0 +1,47 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockRegion returns the byte range that is locked. It lies far beyond the
+// owner record so other processes can still read the PID from the file.
+func lockRegion() *windows.Overlapped {
+	return &windows.Overlapped{OffsetHigh: 0x7fffffff}
+}
+
+// tryLockFile takes a non-blocking exclusive LockFileEx lock on the file
+func tryLockFile(file *os.File) error {
+	flags := uint32(windows.LOCKFILE_EXCLUSIVE_LOCK | windows.LOCKFILE_FAIL_IMMEDIATELY)
+	err := windows.LockFileEx(windows.Handle(file.Fd()), flags, 0, 1, 0, lockRegion())
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLocked
+	}
+	return err
+}
+
+// unlockFile releases a lock taken by tryLockFile
+func unlockFile(file *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, lockRegion())
+}
+
+// instanceSessionID identifies the current Windows logon session
+func instanceSessionID() string {
+	var sessionID uint32
+	if err := windows.ProcessIdToSessionId(windows.GetCurrentProcessId(), &sessionID); err != nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(sessionID), 10)
+}
+
+// checkPrivateDir has nothing to check: the directory is under the user's
+// profile, which other users cannot write to
+func checkPrivateDir(dir string) error {
+	return nil
+}
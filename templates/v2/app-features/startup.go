@@ -1,111 +1,204 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 )
 
-// EnableStartup enables the app to launch on system startup
-func (a *App) EnableStartup() error {
-	switch runtime.GOOS {
-	case "windows":
-		return a.enableStartupWindows()
-	case "darwin":
-		return a.enableStartupMacOS()
-	case "linux":
-		return a.enableStartupLinux()
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+// Autostarter registers the app to launch when the user logs in.
+// Each platform provides its implementation in startup_<os>.go.
+type Autostarter interface {
+	Enable() error
+	Disable() error
+	IsEnabled() (bool, error)
+}
+
+// StartupMode selects the launch mechanism on platforms that offer several
+type StartupMode string
+
+const (
+	// StartupModeAutostart uses the desktop session's login items (default)
+	StartupModeAutostart StartupMode = "autostart"
+	// StartupModeSystemd installs a systemd user service with a restart policy (Linux)
+	StartupModeSystemd StartupMode = "systemd"
+)
+
+// AutostartFlag is added to the login command so the app knows the OS launched it
+const AutostartFlag = "--autostart"
+
+// StartupOptions configures how the app is launched at login
+type StartupOptions struct {
+	// Mode selects autostart or a systemd user service
+	Mode StartupMode `json:"mode"`
+	// Target is the systemd target that pulls in the service (graphical-session.target if empty)
+	Target string `json:"target"`
+	// Args are passed to the app after AutostartFlag, e.g. "--profile=work"
+	Args []string `json:"args"`
+	// Delay postpones the launch by this many seconds (Linux)
+	Delay int `json:"delay"`
+	// HideWhenDisabled keeps the entry with Hidden=true instead of deleting it (Linux)
+	HideWhenDisabled bool `json:"hideWhenDisabled"`
+}
+
+// StartupSettings are the user's startup preferences, persisted across runs
+type StartupSettings struct {
+	Mode StartupMode `json:"mode"`
+	// StartHidden keeps the window hidden, leaving only the tray icon, when launched at login
+	StartHidden bool `json:"startHidden"`
+}
+
+// Metadata used in the OS autostart configuration
+const (
+	startupAppName    = "{{PROJECT_NAME}}"
+	startupAppComment = "Start {{PROJECT_NAME}} when you log in"
+	startupAppIcon    = "{{PROJECT_NAME}}"
+)
+
+// defaultStartupOptions are used by EnableStartup and DisableStartup;
+// Mode is taken from the saved StartupSettings
+var defaultStartupOptions = StartupOptions{Mode: StartupModeAutostart}
+
+// defaultStartupSettings apply until the user changes them
+var defaultStartupSettings = StartupSettings{Mode: StartupModeAutostart, StartHidden: true}
+
+// launchArgs returns the arguments the OS passes to the app at login
+func launchArgs(options StartupOptions) []string {
+	return append([]string{AutostartFlag}, options.Args...)
+}
+
+// LaunchedAtLogin reports whether the OS started the app via its startup entry
+func LaunchedAtLogin() bool {
+	for _, arg := range os.Args[1:] {
+		if arg == AutostartFlag {
+			return true
+		}
 	}
+	return false
 }
 
-// DisableStartup disables the app from launching on system startup
-func (a *App) DisableStartup() error {
-	switch runtime.GOOS {
-	case "windows":
-		return a.disableStartupWindows()
-	case "darwin":
-		return a.disableStartupMacOS()
-	case "linux":
-		return a.disableStartupLinux()
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+// StartHiddenAtLogin reports whether the window should start hidden, which is
+// the case when launched at login and the user has not opted out
+func StartHiddenAtLogin() bool {
+	return LaunchedAtLogin() && loadStartupSettings().StartHidden
+}
+
+// startupSettingsPath returns the path to the startup settings file
+func startupSettingsPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
 	}
+	return filepath.Join(homeDir, ".{{PROJECT_NAME}}", "startup.json"), nil
 }
 
-// IsStartupEnabled checks if startup is enabled
-func (a *App) IsStartupEnabled() (bool, error) {
-	switch runtime.GOOS {
-	case "windows":
-		return a.isStartupEnabledWindows()
-	case "darwin":
-		return a.isStartupEnabledMacOS()
-	case "linux":
-		return a.isStartupEnabledLinux()
-	default:
-		return false, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+// loadStartupSettings returns the saved settings, or the defaults if none are saved
+func loadStartupSettings() StartupSettings {
+	settings := defaultStartupSettings
+
+	settingsPath, err := startupSettingsPath()
+	if err != nil {
+		return settings
 	}
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return settings
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return defaultStartupSettings
+	}
+	if settings.Mode == "" {
+		settings.Mode = StartupModeAutostart
+	}
+	return settings
 }
 
-// Windows implementation
-func (a *App) enableStartupWindows() error {
-	exePath, _ := os.Executable()
-	fmt.Printf("To enable startup on Windows, add to registry:\n")
-	fmt.Printf("HKEY_CURRENT_USER\\Software\\Microsoft\\Windows\\CurrentVersion\\Run\n")
-	fmt.Printf("Name: {{PROJECT_NAME}}, Value: %s\n", exePath)
-	return nil
+// saveStartupSettings persists the user's startup settings
+func saveStartupSettings(settings StartupSettings) error {
+	settingsPath, err := startupSettingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(settingsPath, data, 0644)
 }
 
-func (a *App) disableStartupWindows() error {
-	fmt.Println("Remove registry key: HKEY_CURRENT_USER\\Software\\Microsoft\\Windows\\CurrentVersion\\Run\\{{PROJECT_NAME}}")
-	return nil
+// startupOptions returns the default options with the user's saved mode
+func startupOptions() StartupOptions {
+	options := defaultStartupOptions
+	options.Mode = loadStartupSettings().Mode
+	return options
 }
 
-func (a *App) isStartupEnabledWindows() (bool, error) {
-	// Check registry - requires golang.org/x/sys/windows/registry
-	return false, nil
+// EnableStartup enables the app to launch on system startup
+func (a *App) EnableStartup() error {
+	return newAutostarter(startupOptions()).Enable()
 }
 
-// macOS implementation
-func (a *App) enableStartupMacOS() error {
-	homeDir, _ := os.UserHomeDir()
-	plistPath := filepath.Join(homeDir, "Library/LaunchAgents/com.{{PROJECT_NAME}}.plist")
-	fmt.Printf("Create plist file at: %s\n", plistPath)
+// SetStartupMode selects how EnableStartup launches the app, disabling the previous mode
+func (a *App) SetStartupMode(mode StartupMode) error {
+	if mode != StartupModeAutostart && mode != StartupModeSystemd {
+		return fmt.Errorf("unknown startup mode: %s", mode)
+	}
+	if mode == StartupModeSystemd && runtime.GOOS != "linux" {
+		return fmt.Errorf("startup mode %s is not supported on %s", mode, runtime.GOOS)
+	}
+	settings := loadStartupSettings()
+	if mode == settings.Mode {
+		return nil
+	}
+
+	enabled, _ := a.IsStartupEnabled()
+	if err := a.DisableStartup(); err != nil {
+		return err
+	}
+	settings.Mode = mode
+	if err := saveStartupSettings(settings); err != nil {
+		return err
+	}
+	if enabled {
+		return a.EnableStartup()
+	}
 	return nil
 }
 
-func (a *App) disableStartupMacOS() error {
-	homeDir, _ := os.UserHomeDir()
-	plistPath := filepath.Join(homeDir, "Library/LaunchAgents/com.{{PROJECT_NAME}}.plist")
-	return os.Remove(plistPath)
+// EnableStartupWithOptions enables launching on system startup with custom options
+func (a *App) EnableStartupWithOptions(options StartupOptions) error {
+	return newAutostarter(options).Enable()
+}
+
+// DisableStartup disables the app from launching on system startup
+func (a *App) DisableStartup() error {
+	return newAutostarter(startupOptions()).Disable()
 }
 
-func (a *App) isStartupEnabledMacOS() (bool, error) {
-	homeDir, _ := os.UserHomeDir()
-	plistPath := filepath.Join(homeDir, "Library/LaunchAgents/com.{{PROJECT_NAME}}.plist")
-	_, err := os.Stat(plistPath)
-	return err == nil, nil
+// IsStartupEnabled checks if startup is enabled
+func (a *App) IsStartupEnabled() (bool, error) {
+	return newAutostarter(startupOptions()).IsEnabled()
 }
 
-// Linux implementation
-func (a *App) enableStartupLinux() error {
-	homeDir, _ := os.UserHomeDir()
-	autostartPath := filepath.Join(homeDir, ".config/autostart/{{PROJECT_NAME}}.desktop")
-	fmt.Printf("Create desktop file at: %s\n", autostartPath)
-	return nil
+// WasLaunchedAtLogin reports whether this run was started by the OS at login
+func (a *App) WasLaunchedAtLogin() bool {
+	return LaunchedAtLogin()
 }
 
-func (a *App) disableStartupLinux() error {
-	homeDir, _ := os.UserHomeDir()
-	autostartPath := filepath.Join(homeDir, ".config/autostart/{{PROJECT_NAME}}.desktop")
-	return os.Remove(autostartPath)
+// GetStartupSettings returns the user's startup settings
+func (a *App) GetStartupSettings() StartupSettings {
+	return loadStartupSettings()
 }
 
-func (a *App) isStartupEnabledLinux() (bool, error) {
-	homeDir, _ := os.UserHomeDir()
-	autostartPath := filepath.Join(homeDir, ".config/autostart/{{PROJECT_NAME}}.desktop")
-	_, err := os.Stat(autostartPath)
-	return err == nil, nil
+// SetStartHiddenAtLogin sets whether a login launch starts hidden in the tray
+func (a *App) SetStartHiddenAtLogin(hidden bool) error {
+	settings := loadStartupSettings()
+	settings.StartHidden = hidden
+	return saveStartupSettings(settings)
 }
@@ -0,0 +1,85 @@
+//go:build darwin
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// launchAgentAutostarter uses a per-user LaunchAgent
+type launchAgentAutostarter struct {
+	options StartupOptions
+}
+
+func newAutostarter(options StartupOptions) Autostarter {
+	return launchAgentAutostarter{options: options}
+}
+
+// plistPath returns the LaunchAgent location
+func (launchAgentAutostarter) plistPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, "Library/LaunchAgents/com."+startupAppName+".plist"), nil
+}
+
+func (l launchAgentAutostarter) Enable() error {
+	plistPath, err := l.plistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(plistPath), 0755); err != nil {
+		return err
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	var arguments strings.Builder
+	for _, arg := range append([]string{exePath}, launchArgs(l.options)...) {
+		fmt.Fprintf(&arguments, "\t\t<string>%s</string>\n", html.EscapeString(arg))
+	}
+
+	plistContent := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>com.%s</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>`, html.EscapeString(startupAppName), arguments.String())
+
+	return os.WriteFile(plistPath, []byte(plistContent), 0644)
+}
+
+func (l launchAgentAutostarter) Disable() error {
+	plistPath, err := l.plistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(plistPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l launchAgentAutostarter) IsEnabled() (bool, error) {
+	plistPath, err := l.plistPath()
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(plistPath)
+	return err == nil, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,6 +9,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
 )
 
 // UpdateInfo represents update information
@@ -36,8 +39,21 @@ const (
 	CheckInterval  = 24 * time.Hour
 )
 
+// UpdateService checks GitHub releases for a newer version of the app
+type UpdateService struct{}
+
+// ServiceStartup is a no-op; updates are checked when the frontend asks
+func (u *UpdateService) ServiceStartup(ctx context.Context, options application.ServiceOptions) error {
+	return nil
+}
+
+// ServiceShutdown is a no-op
+func (u *UpdateService) ServiceShutdown() error {
+	return nil
+}
+
 // CheckForUpdates checks if a new version is available
-func (a *App) CheckForUpdates() (*UpdateInfo, error) {
+func (u *UpdateService) CheckForUpdates() (*UpdateInfo, error) {
 	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", GitHubRepo)
 	
 	client := &http.Client{Timeout: 10 * time.Second}
@@ -85,12 +101,12 @@ func (a *App) CheckForUpdates() (*UpdateInfo, error) {
 }
 
 // GetCurrentVersion returns the current app version
-func (a *App) GetCurrentVersion() string {
+func (u *UpdateService) GetCurrentVersion() string {
 	return CurrentVersion
 }
 
 // OpenReleaseURL opens the release page in the browser
-func (a *App) OpenReleaseURL(url string) error {
+func (u *UpdateService) OpenReleaseURL(url string) error {
 	var cmd string
 	var args []string
 
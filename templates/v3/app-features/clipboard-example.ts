@@ -1,9 +1,10 @@
 // Clipboard Utilities Example
-import { GetClipboardText, SetClipboardText, CopyToClipboard, PasteFromClipboard } from '../wailsjs/go/main/App'
+import { ClipboardService } from '../bindings/changeme'
+import { Events } from '@wailsio/runtime'
 
 export async function copyText(text: string) {
   try {
-    await SetClipboardText(text)
+    await ClipboardService.SetClipboardText(text)
     console.log('Copied to clipboard:', text)
     return true
   } catch (error) {
@@ -14,7 +15,7 @@ export async function copyText(text: string) {
 
 export async function pasteText(): Promise<string> {
   try {
-    const text = await GetClipboardText()
+    const text = await ClipboardService.GetClipboardText()
     console.log('Pasted from clipboard:', text)
     return text
   } catch (error) {
@@ -24,7 +25,7 @@ export async function pasteText(): Promise<string> {
 }
 
 export async function copyWithFeedback(text: string) {
-  const success = await CopyToClipboard(text)
+  const success = await ClipboardService.CopyToClipboard(text)
   if (success) {
     console.log('✓ Copied!')
   } else {
@@ -48,3 +49,57 @@ export async function handlePasteButton() {
   // Use the pasted text
   return text
 }
+
+// Example: Clipboard history is off until the user opts in, e.g. from a settings toggle
+export async function setHistoryEnabled(enabled: boolean) {
+  await ClipboardService.SetClipboardHistoryEnabled(enabled)
+}
+
+// Example: Clipboard history (newest first, pinned entries survive purges and clears)
+export async function searchHistory(query: string = '') {
+  return await ClipboardService.GetClipboardHistory(query)
+}
+
+export async function togglePin(id: number, pinned: boolean) {
+  await ClipboardService.PinClipboardEntry(id, pinned)
+}
+
+export async function clearHistory() {
+  await ClipboardService.ClearClipboardHistory()
+}
+
+// Example: Paste the richest format available (Linux needs wl-clipboard or xclip; macOS uses osascript)
+export async function pasteRich() {
+  const formats = await ClipboardService.GetClipboardFormats()
+  if (formats.includes('image/png')) {
+    return { type: 'image', src: 'data:image/png;base64,' + await ClipboardService.GetClipboardImage() }
+  }
+  if (formats.includes('text/uri-list')) {
+    return { type: 'files', paths: await ClipboardService.GetClipboardFiles() }
+  }
+  if (formats.includes('text/html')) {
+    return { type: 'html', html: await ClipboardService.GetClipboardHTML() }
+  }
+  return { type: 'text', text: await ClipboardService.GetClipboardText() }
+}
+
+// Example: Copy a password; it is cleared after 30 seconds unless the user copied something else
+export async function copySecret(secret: string) {
+  await ClipboardService.CopySensitive(secret, 30)
+}
+
+export interface ClipboardChange {
+  type: string
+  size: number
+  content?: string
+}
+
+// Example: React to copies made in other apps; content is only sent if enabled in clipboard_monitor.go
+export async function onClipboardChanged(listener: (change: ClipboardChange) => void) {
+  const unsubscribe = Events.On('clipboard:changed', (event) => listener(event.data as ClipboardChange))
+  await ClipboardService.StartClipboardMonitor()
+  return async () => {
+    unsubscribe()
+    await ClipboardService.StopClipboardMonitor()
+  }
+}
@@ -1,31 +1,195 @@
 package main
 
 import (
-	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+var (
+	errClipboardRead  = errors.New("failed to read clipboard")
+	errClipboardWrite = errors.New("failed to write clipboard")
 )
 
+// ClipboardService exposes the system clipboard to the frontend
+type ClipboardService struct {
+	app *application.App
+	ctx context.Context
+}
+
+// ServiceStartup keeps a handle to the running application and starts the
+// monitor when external changes are recorded in the history
+func (c *ClipboardService) ServiceStartup(ctx context.Context, options application.ServiceOptions) error {
+	c.app = application.Get()
+	c.ctx = ctx
+	if clipboardHistoryOptions.PollExternal {
+		c.StartClipboardMonitor()
+	}
+	return nil
+}
+
+// ServiceShutdown stops the clipboard monitor
+func (c *ClipboardService) ServiceShutdown() error {
+	clipboardWatcher.stop()
+	return nil
+}
+
 // GetClipboardText reads text from the system clipboard
-func (a *App) GetClipboardText() (string, error) {
-	text, err := runtime.ClipboardGetText(a.ctx)
-	return text, err
+func (c *ClipboardService) GetClipboardText() (string, error) {
+	text, ok := c.app.Clipboard.Text()
+	if !ok {
+		return "", errClipboardRead
+	}
+	return text, nil
 }
 
 // SetClipboardText writes text to the system clipboard
-func (a *App) SetClipboardText(text string) error {
-	return runtime.ClipboardSetText(a.ctx, text)
+func (c *ClipboardService) SetClipboardText(text string) error {
+	if !c.app.Clipboard.SetText(text) {
+		return errClipboardWrite
+	}
+	// The copy succeeded; a history that cannot be saved must not fail it
+	if err := sharedClipboardHistory().record(text, ClipboardSourceApp); err != nil {
+		fmt.Println("clipboard history:", err)
+	}
+	return nil
 }
 
 // CopyToClipboard is a helper that copies text and returns success status
-func (a *App) CopyToClipboard(text string) bool {
-	err := runtime.ClipboardSetText(a.ctx, text)
-	return err == nil
+func (c *ClipboardService) CopyToClipboard(text string) bool {
+	return c.SetClipboardText(text) == nil
 }
 
 // PasteFromClipboard is a helper that returns clipboard text or empty string on error
-func (a *App) PasteFromClipboard() string {
-	text, err := runtime.ClipboardGetText(a.ctx)
+func (c *ClipboardService) PasteFromClipboard() string {
+	text, err := c.GetClipboardText()
 	if err != nil {
 		return ""
 	}
 	return text
 }
+
+// CopySensitive copies a secret and clears it after ttlSeconds (default 30)
+// unless the clipboard changed meanwhile; it is never added to the history
+func (c *ClipboardService) CopySensitive(text string, ttlSeconds int) error {
+	return sensitiveClipboard.copySecret(text, time.Duration(ttlSeconds)*time.Second,
+		func(value string) error {
+			if !c.app.Clipboard.SetText(value) {
+				return errClipboardWrite
+			}
+			return nil
+		},
+		c.app.Clipboard.Text)
+}
+
+// GetClipboardFormats lists the formats currently on the clipboard
+func (c *ClipboardService) GetClipboardFormats() ([]string, error) {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		// Only plain text is reachable through the Wails API
+		if text, ok := c.app.Clipboard.Text(); ok && text != "" {
+			return []string{ClipboardFormatText}, nil
+		}
+		return []string{}, nil
+	}
+	return clipboardFormats(backend)
+}
+
+// GetClipboardHTML reads an HTML fragment from the clipboard
+func (c *ClipboardService) GetClipboardHTML() (string, error) {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return "", err
+	}
+	return readClipboardHTML(backend)
+}
+
+// SetClipboardHTML writes an HTML fragment to the clipboard
+func (c *ClipboardService) SetClipboardHTML(html string) error {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return err
+	}
+	return writeClipboardHTML(backend, html)
+}
+
+// GetClipboardImage reads a PNG from the clipboard as base64
+func (c *ClipboardService) GetClipboardImage() (string, error) {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return "", err
+	}
+	return readClipboardImage(backend)
+}
+
+// SetClipboardImage writes a base64 PNG (or PNG data URL) to the clipboard
+func (c *ClipboardService) SetClipboardImage(encoded string) error {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return err
+	}
+	return writeClipboardImage(backend, encoded)
+}
+
+// GetClipboardFiles reads copied file paths from the clipboard
+func (c *ClipboardService) GetClipboardFiles() ([]string, error) {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return nil, err
+	}
+	return readClipboardFiles(backend)
+}
+
+// SetClipboardFiles puts file paths on the clipboard as a URI list
+func (c *ClipboardService) SetClipboardFiles(paths []string) error {
+	backend, err := newClipboardBackend()
+	if err != nil {
+		return err
+	}
+	return writeClipboardFiles(backend, paths)
+}
+
+// StartClipboardMonitor emits ClipboardChangedEvent whenever the clipboard changes
+func (c *ClipboardService) StartClipboardMonitor() {
+	clipboardWatcher.start(c.ctx, c.app.Clipboard.Text, func(change ClipboardChange) {
+		c.app.Event.Emit(ClipboardChangedEvent, change)
+	})
+}
+
+// StopClipboardMonitor stops change events, and external history recording with them
+func (c *ClipboardService) StopClipboardMonitor() {
+	clipboardWatcher.stop()
+}
+
+// IsClipboardMonitorRunning reports whether change events are being emitted
+func (c *ClipboardService) IsClipboardMonitorRunning() bool {
+	return clipboardWatcher.running()
+}
+
+// SetClipboardHistoryEnabled opts in to, or out of, recording copied text
+func (c *ClipboardService) SetClipboardHistoryEnabled(enabled bool) error {
+	return sharedClipboardHistory().setEnabled(enabled)
+}
+
+// IsClipboardHistoryEnabled reports whether copied text is being recorded
+func (c *ClipboardService) IsClipboardHistoryEnabled() bool {
+	return sharedClipboardHistory().enabled()
+}
+
+// GetClipboardHistory returns history entries matching query, newest first
+func (c *ClipboardService) GetClipboardHistory(query string) []ClipboardEntry {
+	return sharedClipboardHistory().search(query)
+}
+
+// PinClipboardEntry pins or unpins a history entry
+func (c *ClipboardService) PinClipboardEntry(id uint64, pinned bool) error {
+	return sharedClipboardHistory().pin(id, pinned)
+}
+
+// ClearClipboardHistory removes all entries except pinned ones
+func (c *ClipboardService) ClearClipboardHistory() error {
+	return sharedClipboardHistory().clear()
+}
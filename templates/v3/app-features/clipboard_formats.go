@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Clipboard formats exposed to the frontend, as MIME types
+const (
+	ClipboardFormatText  = "text/plain"
+	ClipboardFormatHTML  = "text/html"
+	ClipboardFormatPNG   = "image/png"
+	ClipboardFormatFiles = "text/uri-list"
+)
+
+var (
+	// ErrClipboardFormatUnsupported is returned when no backend can handle a format
+	ErrClipboardFormatUnsupported = errors.New("clipboard format not supported on this platform")
+	// ErrClipboardFormatMissing is returned when the clipboard holds no data in a format
+	ErrClipboardFormatMissing = errors.New("clipboard does not contain this format")
+)
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// clipboardBackend reads and writes raw clipboard data by MIME type.
+// Platforms provide one via newClipboardBackend in clipboard_formats_<os>.go.
+type clipboardBackend interface {
+	// Formats lists the native type names currently offered
+	Formats() ([]string, error)
+	Read(format string) ([]byte, error)
+	Write(format string, data []byte) error
+}
+
+// clipboardFormatAliases maps native type names to the exposed formats
+var clipboardFormatAliases = map[string]string{
+	"text/plain":               ClipboardFormatText,
+	"text/plain;charset=utf-8": ClipboardFormatText,
+	"UTF8_STRING":              ClipboardFormatText,
+	"STRING":                   ClipboardFormatText,
+	"TEXT":                     ClipboardFormatText,
+	"text/html":                ClipboardFormatHTML,
+	"image/png":                ClipboardFormatPNG,
+	"text/uri-list":            ClipboardFormatFiles,
+}
+
+// clipboardFormats returns the known formats on offer, in a stable order
+func clipboardFormats(b clipboardBackend) ([]string, error) {
+	native, err := b.Formats()
+	if err != nil {
+		return nil, err
+	}
+
+	offered := map[string]bool{}
+	for _, name := range native {
+		if format, ok := clipboardFormatAliases[strings.TrimSpace(name)]; ok {
+			offered[format] = true
+		}
+	}
+
+	formats := []string{}
+	for _, format := range []string{ClipboardFormatText, ClipboardFormatHTML, ClipboardFormatPNG, ClipboardFormatFiles} {
+		if offered[format] {
+			formats = append(formats, format)
+		}
+	}
+	return formats, nil
+}
+
+// readClipboardHTML returns the HTML fragment on the clipboard
+func readClipboardHTML(b clipboardBackend) (string, error) {
+	data, err := b.Read(ClipboardFormatHTML)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// writeClipboardHTML puts an HTML fragment on the clipboard
+func writeClipboardHTML(b clipboardBackend, html string) error {
+	return b.Write(ClipboardFormatHTML, []byte(html))
+}
+
+// readClipboardImage returns the clipboard PNG, base64 encoded for the frontend
+func readClipboardImage(b clipboardBackend) (string, error) {
+	data, err := b.Read(ClipboardFormatPNG)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.HasPrefix(data, pngSignature) {
+		return "", fmt.Errorf("clipboard image is not a PNG")
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// writeClipboardImage puts a base64-encoded PNG on the clipboard
+func writeClipboardImage(b clipboardBackend, encoded string) error {
+	// Accept data URLs as produced by canvas.toDataURL()
+	if i := strings.Index(encoded, ";base64,"); i >= 0 && strings.HasPrefix(encoded, "data:") {
+		encoded = encoded[i+len(";base64,"):]
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid base64 image: %w", err)
+	}
+	if !bytes.HasPrefix(data, pngSignature) {
+		return fmt.Errorf("image is not a PNG")
+	}
+	return b.Write(ClipboardFormatPNG, data)
+}
+
+// readClipboardFiles returns the local paths in the clipboard's URI list
+func readClipboardFiles(b clipboardBackend) ([]string, error) {
+	data, err := b.Read(ClipboardFormatFiles)
+	if err != nil {
+		return nil, err
+	}
+	return parseFileURIList(string(data)), nil
+}
+
+// writeClipboardFiles puts local paths on the clipboard as a URI list
+func writeClipboardFiles(b clipboardBackend, paths []string) error {
+	list, err := formatFileURIList(paths)
+	if err != nil {
+		return err
+	}
+	return b.Write(ClipboardFormatFiles, []byte(list))
+}
+
+// parseFileURIList extracts local paths from an RFC 2483 URI list, skipping
+// comments and non-file URIs
+func parseFileURIList(list string) []string {
+	paths := []string{}
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		u, err := url.Parse(line)
+		if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+			continue
+		}
+		path := u.Path
+		if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+			path = path[1:]
+		}
+		paths = append(paths, filepath.FromSlash(path))
+	}
+	return paths
+}
+
+// formatFileURIList renders absolute paths as an RFC 2483 URI list
+func formatFileURIList(paths []string) (string, error) {
+	var list strings.Builder
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+		slashed := filepath.ToSlash(abs)
+		if !strings.HasPrefix(slashed, "/") {
+			slashed = "/" + slashed
+		}
+		list.WriteString((&url.URL{Scheme: "file", Path: slashed}).String())
+		list.WriteString("\r\n")
+	}
+	return list.String(), nil
+}
@@ -0,0 +1,174 @@
+//go:build darwin
+
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// pasteboardTypes maps the exposed formats to NSPasteboard type identifiers
+var pasteboardTypes = map[string]string{
+	ClipboardFormatText:  "public.utf8-plain-text",
+	ClipboardFormatHTML:  "public.html",
+	ClipboardFormatPNG:   "public.png",
+	ClipboardFormatFiles: "public.file-url",
+}
+
+// pasteboardScript drives NSPasteboard through the Objective-C bridge of
+// JavaScript for Automation. Data travels base64 encoded on stdout, and in
+// temporary files for writes, since arguments are limited in size.
+const pasteboardScript = `ObjC.import('AppKit')
+function run(argv) {
+  var pb = $.NSPasteboard.generalPasteboard
+  var command = argv[0]
+  if (command === 'types') {
+    var types = pb.types, out = []
+    for (var i = 0; !types.isNil() && i < types.count; i++) out.push(ObjC.unwrap(types.objectAtIndex(i)))
+    return out.join('\n')
+  }
+  if (command === 'read-files') {
+    var items = pb.pasteboardItems, urls = []
+    for (var i = 0; !items.isNil() && i < items.count; i++) {
+      var url = ObjC.unwrap(items.objectAtIndex(i).stringForType(argv[1]))
+      if (url) urls.push(url)
+    }
+    return urls.join('\n')
+  }
+  if (command === 'read') {
+    var data = pb.dataForType(argv[1])
+    return data.isNil() ? '' : ObjC.unwrap(data.base64EncodedStringWithOptions(0))
+  }
+  if (command === 'write') {
+    pb.clearContents
+    for (var i = 1; i + 1 < argv.length; i += 2) {
+      if (argv[i] === 'public.file-url') {
+        var list = ObjC.unwrap($.NSString.stringWithContentsOfFileEncodingError(argv[i + 1], $.NSUTF8StringEncoding, null))
+        var files = list.split('\n').filter(function (p) { return p !== '' })
+        if (!pb.writeObjects($(files.map(function (p) { return $.NSURL.fileURLWithPath(p) })))) throw new Error('cannot write files')
+      } else if (!pb.setDataForType($.NSData.dataWithContentsOfFile(argv[i + 1]), argv[i])) {
+        throw new Error('cannot write ' + argv[i])
+      }
+    }
+    return ''
+  }
+  throw new Error('unknown command ' + command)
+}`
+
+// pasteboardClipboard reads and writes the macOS general pasteboard. The Wails
+// clipboard API only handles plain text, and osascript avoids extra cgo.
+type pasteboardClipboard struct{}
+
+func newClipboardBackend() (clipboardBackend, error) {
+	if _, err := exec.LookPath("osascript"); err != nil {
+		return nil, fmt.Errorf("%w: osascript not found", ErrClipboardFormatUnsupported)
+	}
+	return pasteboardClipboard{}, nil
+}
+
+// run executes pasteboardScript with args and returns its trimmed result
+func (c pasteboardClipboard) run(args ...string) (string, error) {
+	cmd := exec.Command("osascript", append([]string{"-l", "JavaScript", "-e", pasteboardScript}, args...)...)
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return "", fmt.Errorf("osascript: %s", strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (c pasteboardClipboard) Formats() ([]string, error) {
+	out, err := c.run("types")
+	if err != nil {
+		return nil, err
+	}
+	offered := map[string]bool{}
+	for _, name := range strings.Split(out, "\n") {
+		offered[name] = true
+	}
+
+	formats := []string{}
+	for format, pasteboardType := range pasteboardTypes {
+		if offered[pasteboardType] {
+			formats = append(formats, format)
+		}
+	}
+	return formats, nil
+}
+
+func (c pasteboardClipboard) Read(format string) ([]byte, error) {
+	pasteboardType, ok := pasteboardTypes[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrClipboardFormatUnsupported, format)
+	}
+
+	// Each copied file is a separate pasteboard item holding one file URL
+	if format == ClipboardFormatFiles {
+		out, err := c.run("read-files", pasteboardType)
+		if err != nil {
+			return nil, err
+		}
+		if out == "" {
+			return nil, ErrClipboardFormatMissing
+		}
+		return []byte(strings.ReplaceAll(out, "\n", "\r\n") + "\r\n"), nil
+	}
+
+	out, err := c.run("read", pasteboardType)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, ErrClipboardFormatMissing
+	}
+	return base64.StdEncoding.DecodeString(out)
+}
+
+func (c pasteboardClipboard) Write(format string, data []byte) error {
+	return c.WriteTargets(map[string][]byte{format: data})
+}
+
+// WriteTargets publishes several formats as one pasteboard item, replacing the
+// previous contents; keys are exposed formats or raw pasteboard types
+func (c pasteboardClipboard) WriteTargets(targets map[string][]byte) error {
+	dir, err := os.MkdirTemp("", "clipboard-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	args := []string{"write"}
+	for format, data := range targets {
+		pasteboardType, ok := pasteboardTypes[format]
+		if !ok {
+			pasteboardType = format
+		}
+		// Files are written as URLs so Finder can paste them
+		if format == ClipboardFormatFiles {
+			data = []byte(strings.Join(parseFileURIList(string(data)), "\n"))
+		}
+
+		file, err := os.CreateTemp(dir, "target-*")
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		args = append(args, pasteboardType, file.Name())
+	}
+
+	_, err = c.run(args...)
+	return err
+}
@@ -0,0 +1,91 @@
+//go:build linux
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// commandClipboard drives wl-clipboard on Wayland or xclip on X11, since the
+// Wails clipboard API only handles plain text
+type commandClipboard struct {
+	wayland bool
+}
+
+func newClipboardBackend() (clipboardBackend, error) {
+	if os.Getenv("WAYLAND_DISPLAY") != "" && commandsAvailable("wl-paste", "wl-copy") {
+		return commandClipboard{wayland: true}, nil
+	}
+	if os.Getenv("DISPLAY") != "" && commandsAvailable("xclip") {
+		return commandClipboard{wayland: false}, nil
+	}
+	return nil, fmt.Errorf("%w: install wl-clipboard (Wayland) or xclip (X11)", ErrClipboardFormatUnsupported)
+}
+
+// commandsAvailable reports whether every named command is on PATH
+func commandsAvailable(names ...string) bool {
+	for _, name := range names {
+		if _, err := exec.LookPath(name); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (c commandClipboard) Formats() ([]string, error) {
+	var cmd *exec.Cmd
+	if c.wayland {
+		cmd = exec.Command("wl-paste", "--list-types")
+	} else {
+		cmd = exec.Command("xclip", "-selection", "clipboard", "-o", "-t", "TARGETS")
+	}
+
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// Both tools exit non-zero when the clipboard is empty
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+func (c commandClipboard) Read(format string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if c.wayland {
+		cmd = exec.Command("wl-paste", "--no-newline", "--type", format)
+	} else {
+		cmd = exec.Command("xclip", "-selection", "clipboard", "-o", "-t", format)
+	}
+
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil, ErrClipboardFormatMissing
+	}
+	return out, err
+}
+
+func (c commandClipboard) Write(format string, data []byte) error {
+	var cmd *exec.Cmd
+	if c.wayland {
+		cmd = exec.Command("wl-copy", "--type", format)
+	} else {
+		cmd = exec.Command("xclip", "-selection", "clipboard", "-i", "-t", format)
+	}
+	cmd.Stdin = bytes.NewReader(data)
+
+	// No output capture: both tools fork a child that keeps serving the
+	// selection, which would hold a stdout pipe open indefinitely
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", cmd.Args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !windows && !darwin
+
+package main
+
+// newClipboardBackend has no rich-format backend here yet; plain text still
+// goes through the Wails clipboard API
+func newClipboardBackend() (clipboardBackend, error) {
+	return nil, ErrClipboardFormatUnsupported
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fakeClipboard is an in-memory clipboardBackend keyed by native type name
+type fakeClipboard struct {
+	data map[string][]byte
+}
+
+func newFakeClipboard() *fakeClipboard {
+	return &fakeClipboard{data: map[string][]byte{}}
+}
+
+func (f *fakeClipboard) Formats() ([]string, error) {
+	formats := []string{}
+	for format := range f.data {
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+func (f *fakeClipboard) Read(format string) ([]byte, error) {
+	data, ok := f.data[format]
+	if !ok {
+		return nil, ErrClipboardFormatMissing
+	}
+	return data, nil
+}
+
+func (f *fakeClipboard) Write(format string, data []byte) error {
+	// A new owner replaces every format, as a real clipboard does
+	f.data = map[string][]byte{format: data}
+	return nil
+}
+
+func TestClipboardFormatsNormalizesNativeNames(t *testing.T) {
+	fake := newFakeClipboard()
+	fake.data = map[string][]byte{
+		"TARGETS":       nil,
+		"UTF8_STRING":   []byte("hi"),
+		"text/html":     []byte("<b>hi</b>"),
+		"image/png":     pngSignature,
+		"text/uri-list": []byte("file:///tmp/a\r\n"),
+		"TIMESTAMP":     nil,
+	}
+
+	got, err := clipboardFormats(fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{ClipboardFormatText, ClipboardFormatHTML, ClipboardFormatPNG, ClipboardFormatFiles}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("formats = %v, want %v", got, want)
+	}
+}
+
+func TestClipboardHTMLRoundTrip(t *testing.T) {
+	fake := newFakeClipboard()
+	if err := writeClipboardHTML(fake, "<table><tr><td>1</td></tr></table>"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readClipboardHTML(fake)
+	if err != nil || got != "<table><tr><td>1</td></tr></table>" {
+		t.Errorf("readClipboardHTML() = %q, %v", got, err)
+	}
+}
+
+func TestClipboardImageRoundTrip(t *testing.T) {
+	fake := newFakeClipboard()
+	png := append(append([]byte{}, pngSignature...), 0, 1, 2, 3)
+	encoded := base64.StdEncoding.EncodeToString(png)
+
+	if err := writeClipboardImage(fake, "data:image/png;base64,"+encoded); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readClipboardImage(fake)
+	if err != nil || got != encoded {
+		t.Errorf("readClipboardImage() = %q, %v; want %q", got, err, encoded)
+	}
+
+	if err := writeClipboardImage(fake, base64.StdEncoding.EncodeToString([]byte("GIF89a"))); err == nil {
+		t.Error("writeClipboardImage accepted a non-PNG image")
+	}
+	if err := writeClipboardImage(fake, "not base64!"); err == nil {
+		t.Error("writeClipboardImage accepted invalid base64")
+	}
+}
+
+func TestClipboardFilesRoundTrip(t *testing.T) {
+	fake := newFakeClipboard()
+	dir := t.TempDir()
+	paths := []string{filepath.Join(dir, "report 2026.pdf"), filepath.Join(dir, "100%.txt")}
+
+	if err := writeClipboardFiles(fake, paths); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readClipboardFiles(fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, paths) {
+		t.Errorf("files = %v, want %v", got, paths)
+	}
+}
+
+func TestParseFileURIListSkipsCommentsAndRemoteURIs(t *testing.T) {
+	list := "# copied by a file manager\r\nfile:///tmp/a%20b\r\nhttps://example.com/x\r\nfile://otherhost/tmp/c\r\nfile://localhost/tmp/d\r\n"
+
+	got := parseFileURIList(list)
+	want := []string{filepath.FromSlash("/tmp/a b"), filepath.FromSlash("/tmp/d")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paths = %v, want %v", got, want)
+	}
+}
+
+func TestClipboardMissingFormat(t *testing.T) {
+	fake := newFakeClipboard()
+	if _, err := readClipboardHTML(fake); !errors.Is(err, ErrClipboardFormatMissing) {
+		t.Errorf("readClipboardHTML(empty) error = %v, want ErrClipboardFormatMissing", err)
+	}
+}
+
+func TestCFHTMLRoundTrip(t *testing.T) {
+	fragment := "<b>café</b> & <i>more</i>"
+	encoded := encodeCFHTML(fragment)
+	got, err := decodeCFHTML(encoded)
+	if err != nil || got != fragment {
+		t.Errorf("decodeCFHTML(encodeCFHTML()) = %q, %v; want %q", got, err, fragment)
+	}
+
+	// Browsers write offsets that may be wrong; the comments still delimit the fragment
+	broken := []byte("Version:0.9\r\nStartFragment:9999\r\nEndFragment:10000\r\n<html><body><!--StartFragment--><p>x</p><!--EndFragment--></body></html>\x00")
+	if got, err := decodeCFHTML(broken); err != nil || got != "<p>x</p>" {
+		t.Errorf("decodeCFHTML(bad offsets) = %q, %v", got, err)
+	}
+}
+
+func TestDropFilesRoundTrip(t *testing.T) {
+	paths := []string{`C:\Users\me\report 2026.pdf`, `D:\übung.txt`}
+	got, err := decodeDropFiles(encodeDropFiles(paths))
+	if err != nil || !reflect.DeepEqual(got, paths) {
+		t.Errorf("decodeDropFiles(encodeDropFiles()) = %q, %v", got, err)
+	}
+
+	// Some applications still write ANSI file lists
+	ansi := make([]byte, dropFilesHeaderSize)
+	binary.LittleEndian.PutUint32(ansi, dropFilesHeaderSize)
+	ansi = append(ansi, "C:\\a.txt\x00C:\\b.txt\x00\x00"...)
+	if got, err := decodeDropFiles(ansi); err != nil || !reflect.DeepEqual(got, []string{`C:\a.txt`, `C:\b.txt`}) {
+		t.Errorf("decodeDropFiles(ansi) = %q, %v", got, err)
+	}
+}
+
+func TestDIBRoundTrip(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	img.SetNRGBA(2, 1, color.NRGBA{B: 255, A: 128})
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		t.Fatal(err)
+	}
+
+	dib, err := pngToDIB(encoded.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted, err := dibToPNG(dib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(converted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []image.Point{{0, 0}, {2, 1}, {1, 0}} {
+		want := img.NRGBAAt(p.X, p.Y)
+		if got := color.NRGBAModel.Convert(decoded.At(p.X, p.Y)); got != want {
+			t.Errorf("pixel %v = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestDIBWithoutAlphaIsOpaque(t *testing.T) {
+	// A 1x1 24-bit bitmap, as written by screenshots, with a padded row
+	dib := make([]byte, bitmapInfoHeaderSize, bitmapInfoHeaderSize+4)
+	binary.LittleEndian.PutUint32(dib[0:], bitmapInfoHeaderSize)
+	binary.LittleEndian.PutUint32(dib[4:], 1)
+	binary.LittleEndian.PutUint32(dib[8:], 1)
+	binary.LittleEndian.PutUint16(dib[12:], 1)
+	binary.LittleEndian.PutUint16(dib[14:], 24)
+	dib = append(dib, 0x10, 0x20, 0x30, 0)
+
+	converted, err := dibToPNG(dib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(converted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := color.NRGBAModel.Convert(decoded.At(0, 0)); got != (color.NRGBA{R: 0x30, G: 0x20, B: 0x10, A: 0xFF}) {
+		t.Errorf("pixel = %v, want opaque #302010", got)
+	}
+
+	binary.LittleEndian.PutUint16(dib[14:], 8)
+	if _, err := dibToPNG(dib); !errors.Is(err, ErrClipboardFormatUnsupported) {
+		t.Errorf("dibToPNG(8-bit) error = %v, want ErrClipboardFormatUnsupported", err)
+	}
+}
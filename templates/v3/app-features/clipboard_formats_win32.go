@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// The Windows clipboard stores rich formats in its own encodings. They are
+// converted here, without build tags, so the conversions can be tested on
+// every platform.
+
+const (
+	cfHTMLStartFragment = "<!--StartFragment-->"
+	cfHTMLEndFragment   = "<!--EndFragment-->"
+
+	// dropFilesHeaderSize is sizeof(DROPFILES): pFiles, pt.x, pt.y, fNC, fWide
+	dropFilesHeaderSize = 20
+
+	bitmapInfoHeaderSize = 40
+	biRGB                = 0
+	biBitfields          = 3
+
+	// maxClipboardImagePixels bounds images decoded from the clipboard
+	maxClipboardImagePixels = 1 << 26
+)
+
+// encodeCFHTML wraps an HTML fragment in the CF_HTML "HTML Format" header,
+// whose byte offsets are written as fixed-width numbers so they can be filled
+// in after the header's own length is known
+func encodeCFHTML(fragment string) []byte {
+	const header = "Version:0.9\r\nStartHTML:%010d\r\nEndHTML:%010d\r\nStartFragment:%010d\r\nEndFragment:%010d\r\n"
+	headerLen := len(fmt.Sprintf(header, 0, 0, 0, 0))
+	prefix := "<html><body>\r\n" + cfHTMLStartFragment
+	suffix := cfHTMLEndFragment + "\r\n</body></html>"
+
+	startHTML := headerLen
+	startFragment := startHTML + len(prefix)
+	endFragment := startFragment + len(fragment)
+	endHTML := endFragment + len(suffix)
+	return []byte(fmt.Sprintf(header, startHTML, endHTML, startFragment, endFragment) + prefix + fragment + suffix)
+}
+
+// decodeCFHTML returns the fragment from CF_HTML data, falling back to the
+// fragment comments when the offsets are missing or wrong
+func decodeCFHTML(data []byte) (string, error) {
+	data = bytes.TrimRight(data, "\x00")
+	offsets := map[string]int{}
+	for _, line := range strings.SplitN(string(data), "\n", 8) {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(value); err == nil {
+			offsets[key] = n
+		}
+	}
+
+	start, hasStart := offsets["StartFragment"]
+	end, hasEnd := offsets["EndFragment"]
+	if hasStart && hasEnd && start >= 0 && start <= end && end <= len(data) {
+		return string(data[start:end]), nil
+	}
+
+	html := string(data)
+	if i := strings.Index(html, cfHTMLStartFragment); i >= 0 {
+		html = html[i+len(cfHTMLStartFragment):]
+		if j := strings.Index(html, cfHTMLEndFragment); j >= 0 {
+			return html[:j], nil
+		}
+	}
+	return "", errors.New("clipboard HTML has no fragment")
+}
+
+// encodeDropFiles builds a CF_HDROP DROPFILES block listing paths as wide strings
+func encodeDropFiles(paths []string) []byte {
+	data := make([]byte, dropFilesHeaderSize)
+	binary.LittleEndian.PutUint32(data[0:], dropFilesHeaderSize) // pFiles
+	binary.LittleEndian.PutUint32(data[16:], 1)                  // fWide
+	for _, path := range paths {
+		for _, unit := range utf16.Encode([]rune(path)) {
+			data = binary.LittleEndian.AppendUint16(data, unit)
+		}
+		data = binary.LittleEndian.AppendUint16(data, 0)
+	}
+	// The list ends with an empty string
+	return binary.LittleEndian.AppendUint16(data, 0)
+}
+
+// decodeDropFiles returns the paths in a CF_HDROP DROPFILES block
+func decodeDropFiles(data []byte) ([]string, error) {
+	if len(data) < dropFilesHeaderSize {
+		return nil, errors.New("clipboard file list is truncated")
+	}
+	offset := binary.LittleEndian.Uint32(data[0:])
+	wide := binary.LittleEndian.Uint32(data[16:]) != 0
+	if offset < dropFilesHeaderSize || int(offset) > len(data) {
+		return nil, errors.New("clipboard file list is malformed")
+	}
+
+	paths := []string{}
+	rest := data[offset:]
+	for {
+		var path string
+		if wide {
+			var units []uint16
+			for len(rest) >= 2 {
+				unit := binary.LittleEndian.Uint16(rest)
+				rest = rest[2:]
+				if unit == 0 {
+					break
+				}
+				units = append(units, unit)
+			}
+			path = string(utf16.Decode(units))
+		} else {
+			end := bytes.IndexByte(rest, 0)
+			if end < 0 {
+				path, rest = string(rest), nil
+			} else {
+				path, rest = string(rest[:end]), rest[end+1:]
+			}
+		}
+		if path == "" {
+			return paths, nil
+		}
+		paths = append(paths, path)
+	}
+}
+
+// dibToPNG converts a CF_DIB or CF_DIBV5 bitmap, as produced by screenshots and
+// most image editors, to PNG. Only uncompressed 24 and 32-bit bitmaps are
+// supported, which is what Windows itself synthesizes.
+func dibToPNG(data []byte) ([]byte, error) {
+	if len(data) < bitmapInfoHeaderSize {
+		return nil, errors.New("clipboard bitmap is truncated")
+	}
+	headerSize := int(binary.LittleEndian.Uint32(data[0:]))
+	width := int(int32(binary.LittleEndian.Uint32(data[4:])))
+	height := int(int32(binary.LittleEndian.Uint32(data[8:])))
+	bitCount := int(binary.LittleEndian.Uint16(data[14:]))
+	compression := binary.LittleEndian.Uint32(data[16:])
+
+	topDown := height < 0
+	if topDown {
+		height = -height
+	}
+	if headerSize < bitmapInfoHeaderSize || width <= 0 || height <= 0 || width*height > maxClipboardImagePixels {
+		return nil, errors.New("clipboard bitmap has an invalid size")
+	}
+	if (bitCount != 24 && bitCount != 32) || (compression != biRGB && compression != biBitfields) ||
+		(compression == biBitfields && bitCount != 32) {
+		return nil, fmt.Errorf("%w: %d-bit bitmap with compression %d", ErrClipboardFormatUnsupported, bitCount, compression)
+	}
+
+	pixels := headerSize
+	if compression == biBitfields {
+		// The masks follow a plain BITMAPINFOHEADER and are part of larger headers
+		masks := data[bitmapInfoHeaderSize:]
+		if headerSize == bitmapInfoHeaderSize {
+			pixels += 12
+		}
+		if len(masks) < 12 || binary.LittleEndian.Uint32(masks[0:]) != 0x00FF0000 ||
+			binary.LittleEndian.Uint32(masks[4:]) != 0x0000FF00 || binary.LittleEndian.Uint32(masks[8:]) != 0x000000FF {
+			return nil, fmt.Errorf("%w: bitmap with custom color masks", ErrClipboardFormatUnsupported)
+		}
+	}
+
+	stride := (width*bitCount + 31) / 32 * 4
+	if pixels+stride*height > len(data) {
+		return nil, errors.New("clipboard bitmap is truncated")
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		row := y
+		if !topDown {
+			row = height - 1 - y
+		}
+		src := data[pixels+row*stride:]
+		for x := 0; x < width; x++ {
+			b, g, r, a := src[0], src[1], src[2], byte(0xFF)
+			if bitCount == 32 {
+				a = src[3]
+				hasAlpha = hasAlpha || a != 0
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: r, G: g, B: b, A: a})
+			src = src[bitCount/8:]
+		}
+	}
+	// Most applications leave the fourth byte of 32-bit bitmaps at zero
+	if bitCount == 32 && !hasAlpha {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 0xFF
+		}
+	}
+
+	var out bytes.Buffer
+	if err := png.Encode(&out, img); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// pngToDIB converts a PNG to a 32-bit bottom-up CF_DIB for applications that
+// do not read the registered PNG format
+func pngToDIB(data []byte) ([]byte, error) {
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxClipboardImagePixels {
+		return nil, errors.New("image is too large for the clipboard")
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dib := make([]byte, bitmapInfoHeaderSize, bitmapInfoHeaderSize+width*height*4)
+	binary.LittleEndian.PutUint32(dib[0:], bitmapInfoHeaderSize)
+	binary.LittleEndian.PutUint32(dib[4:], uint32(width))
+	binary.LittleEndian.PutUint32(dib[8:], uint32(height))
+	binary.LittleEndian.PutUint16(dib[12:], 1)  // biPlanes
+	binary.LittleEndian.PutUint16(dib[14:], 32) // biBitCount
+	binary.LittleEndian.PutUint32(dib[20:], uint32(width*height*4))
+
+	for y := bounds.Max.Y - 1; y >= bounds.Min.Y; y-- {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			dib = append(dib, c.B, c.G, c.R, c.A)
+		}
+	}
+	return dib, nil
+}
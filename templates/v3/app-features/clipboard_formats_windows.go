@@ -0,0 +1,220 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	procGetClipboardData           = clipboardUser32.NewProc("GetClipboardData")
+	procEnumClipboardFormats       = clipboardUser32.NewProc("EnumClipboardFormats")
+	procIsClipboardFormatAvailable = clipboardUser32.NewProc("IsClipboardFormatAvailable")
+	procGlobalSize                 = clipboardKernel32.NewProc("GlobalSize")
+)
+
+const (
+	cfDIB   = 8
+	cfHDrop = 15
+	cfDIBV5 = 17
+)
+
+// win32Clipboard reads and writes the Windows clipboard formats behind each
+// MIME type: CF_UNICODETEXT, "HTML Format", "PNG" (with CF_DIB for older
+// applications) and CF_HDROP
+type win32Clipboard struct{}
+
+func newClipboardBackend() (clipboardBackend, error) {
+	return win32Clipboard{}, nil
+}
+
+// registeredFormat returns the ID of a named clipboard format such as "HTML Format"
+func registeredFormat(name string) (uintptr, error) {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+	format, _, err := procRegisterClipboardFormatW.Call(uintptr(unsafe.Pointer(namePtr)))
+	if format == 0 {
+		return 0, err
+	}
+	return format, nil
+}
+
+func (c win32Clipboard) Formats() ([]string, error) {
+	htmlFormat, err := registeredFormat("HTML Format")
+	if err != nil {
+		return nil, err
+	}
+	pngFormat, err := registeredFormat("PNG")
+	if err != nil {
+		return nil, err
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := openClipboard(); err != nil {
+		return nil, err
+	}
+	defer procCloseClipboard.Call()
+
+	formats := []string{}
+	for format, _, _ := procEnumClipboardFormats.Call(0); format != 0; format, _, _ = procEnumClipboardFormats.Call(format) {
+		switch format {
+		case cfUnicodeText:
+			formats = append(formats, ClipboardFormatText)
+		case htmlFormat:
+			formats = append(formats, ClipboardFormatHTML)
+		case pngFormat, cfDIB, cfDIBV5:
+			formats = append(formats, ClipboardFormatPNG)
+		case cfHDrop:
+			formats = append(formats, ClipboardFormatFiles)
+		}
+	}
+	return formats, nil
+}
+
+func (c win32Clipboard) Read(format string) ([]byte, error) {
+	switch format {
+	case ClipboardFormatText:
+		data, err := readClipboardData(cfUnicodeText)
+		if err != nil {
+			return nil, err
+		}
+		units := unsafe.Slice((*uint16)(unsafe.Pointer(&data[0])), len(data)/2)
+		return []byte(windows.UTF16ToString(units)), nil
+
+	case ClipboardFormatHTML:
+		htmlFormat, err := registeredFormat("HTML Format")
+		if err != nil {
+			return nil, err
+		}
+		data, err := readClipboardData(htmlFormat)
+		if err != nil {
+			return nil, err
+		}
+		fragment, err := decodeCFHTML(data)
+		return []byte(fragment), err
+
+	case ClipboardFormatPNG:
+		pngFormat, err := registeredFormat("PNG")
+		if err != nil {
+			return nil, err
+		}
+		if data, err := readClipboardData(pngFormat); !errors.Is(err, ErrClipboardFormatMissing) {
+			return data, err
+		}
+		// Screenshots only offer a bitmap; Windows synthesizes CF_DIB from CF_DIBV5
+		data, err := readClipboardData(cfDIB)
+		if err != nil {
+			return nil, err
+		}
+		return dibToPNG(data)
+
+	case ClipboardFormatFiles:
+		data, err := readClipboardData(cfHDrop)
+		if err != nil {
+			return nil, err
+		}
+		paths, err := decodeDropFiles(data)
+		if err != nil {
+			return nil, err
+		}
+		list, err := formatFileURIList(paths)
+		return []byte(list), err
+	}
+	return nil, fmt.Errorf("%w: %s", ErrClipboardFormatUnsupported, format)
+}
+
+func (c win32Clipboard) Write(format string, data []byte) error {
+	formats := map[uintptr][]byte{}
+	switch format {
+	case ClipboardFormatText:
+		encoded, err := windows.UTF16FromString(string(data))
+		if err != nil {
+			return err
+		}
+		formats[cfUnicodeText] = unsafe.Slice((*byte)(unsafe.Pointer(&encoded[0])), len(encoded)*2)
+
+	case ClipboardFormatHTML:
+		htmlFormat, err := registeredFormat("HTML Format")
+		if err != nil {
+			return err
+		}
+		formats[htmlFormat] = encodeCFHTML(string(data))
+
+	case ClipboardFormatPNG:
+		pngFormat, err := registeredFormat("PNG")
+		if err != nil {
+			return err
+		}
+		dib, err := pngToDIB(data)
+		if err != nil {
+			return err
+		}
+		formats[pngFormat] = data
+		formats[cfDIB] = dib
+
+	case ClipboardFormatFiles:
+		formats[cfHDrop] = encodeDropFiles(parseFileURIList(string(data)))
+
+	default:
+		return fmt.Errorf("%w: %s", ErrClipboardFormatUnsupported, format)
+	}
+
+	// Clipboard ownership is tied to the calling thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := openClipboard(); err != nil {
+		return err
+	}
+	defer procCloseClipboard.Call()
+
+	if r, _, err := procEmptyClipboard.Call(); r == 0 {
+		return err
+	}
+	for id, value := range formats {
+		if err := setClipboardData(id, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readClipboardData copies the clipboard's data in a format, or returns
+// ErrClipboardFormatMissing if it is not offered
+func readClipboardData(format uintptr) ([]byte, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if r, _, _ := procIsClipboardFormatAvailable.Call(format); r == 0 {
+		return nil, ErrClipboardFormatMissing
+	}
+	if err := openClipboard(); err != nil {
+		return nil, err
+	}
+	defer procCloseClipboard.Call()
+
+	handle, _, err := procGetClipboardData.Call(format)
+	if handle == 0 {
+		return nil, err
+	}
+	size, _, _ := procGlobalSize.Call(handle)
+	ptr, _, err := procGlobalLock.Call(handle)
+	if ptr == 0 {
+		return nil, err
+	}
+	defer procGlobalUnlock.Call(handle)
+	if size == 0 {
+		return nil, ErrClipboardFormatMissing
+	}
+
+	data := make([]byte, size)
+	procRtlMoveMemory.Call(uintptr(unsafe.Pointer(&data[0])), ptr, size)
+	return data, nil
+}
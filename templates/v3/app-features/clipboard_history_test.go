@@ -0,0 +1,146 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestClipboardHistory(t *testing.T, options ClipboardHistoryOptions) (*clipboardHistory, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	h := newClipboardHistory(options, filepath.Join(t.TempDir(), "clipboard-history.json"))
+	h.now = func() time.Time { return now }
+	return h, &now
+}
+
+func historyTexts(entries []ClipboardEntry) []string {
+	texts := make([]string, len(entries))
+	for i, entry := range entries {
+		texts[i] = entry.Text
+	}
+	return texts
+}
+
+func TestClipboardHistoryDeduplicates(t *testing.T) {
+	h, _ := newTestClipboardHistory(t, ClipboardHistoryOptions{Enabled: true, MaxEntries: 10})
+
+	h.record("one", ClipboardSourceApp)
+	h.record("two", ClipboardSourceApp)
+	h.record("one", ClipboardSourceExternal)
+
+	entries := h.search("")
+	if got := historyTexts(entries); len(got) != 2 || got[0] != "one" || got[1] != "two" {
+		t.Fatalf("entries = %v, want [one two]", got)
+	}
+	if entries[0].ID != 1 || entries[0].Source != ClipboardSourceExternal {
+		t.Errorf("moved entry = %+v, want ID 1 from external", entries[0])
+	}
+}
+
+func TestClipboardHistoryBoundsKeepPinned(t *testing.T) {
+	h, _ := newTestClipboardHistory(t, ClipboardHistoryOptions{Enabled: true, MaxEntries: 2})
+
+	h.record("pinned", ClipboardSourceApp)
+	if err := h.pin(1, true); err != nil {
+		t.Fatal(err)
+	}
+	h.record("a", ClipboardSourceApp)
+	h.record("b", ClipboardSourceApp)
+	h.record("c", ClipboardSourceApp)
+
+	if got := historyTexts(h.search("")); len(got) != 3 || got[0] != "c" || got[1] != "b" || got[2] != "pinned" {
+		t.Fatalf("entries = %v, want [c b pinned]", got)
+	}
+	if err := h.pin(99, true); err != ErrClipboardEntryNotFound {
+		t.Errorf("pin(unknown) = %v, want ErrClipboardEntryNotFound", err)
+	}
+}
+
+func TestClipboardHistoryPurgesByAge(t *testing.T) {
+	h, now := newTestClipboardHistory(t, ClipboardHistoryOptions{Enabled: true, MaxAge: time.Hour})
+
+	h.record("old", ClipboardSourceApp)
+	*now = now.Add(2 * time.Hour)
+	h.record("new", ClipboardSourceApp)
+
+	if got := historyTexts(h.search("")); len(got) != 1 || got[0] != "new" {
+		t.Fatalf("entries = %v, want [new]", got)
+	}
+}
+
+func TestClipboardHistorySearchAndClear(t *testing.T) {
+	h, _ := newTestClipboardHistory(t, ClipboardHistoryOptions{Enabled: true})
+
+	h.record("Hello World", ClipboardSourceApp)
+	h.record("goodbye", ClipboardSourceApp)
+	h.pin(1, true)
+
+	if got := historyTexts(h.search("WORLD")); len(got) != 1 || got[0] != "Hello World" {
+		t.Fatalf("search = %v, want [Hello World]", got)
+	}
+	if err := h.clear(); err != nil {
+		t.Fatal(err)
+	}
+	if got := historyTexts(h.search("")); len(got) != 1 || got[0] != "Hello World" {
+		t.Fatalf("after clear = %v, want pinned entry only", got)
+	}
+}
+
+func TestClipboardHistoryPersists(t *testing.T) {
+	h, _ := newTestClipboardHistory(t, ClipboardHistoryOptions{Enabled: true})
+	h.record("saved", ClipboardSourceApp)
+
+	reloaded := newClipboardHistory(h.options, h.path)
+	reloaded.now = h.now
+	if got := historyTexts(reloaded.search("")); len(got) != 1 || got[0] != "saved" {
+		t.Fatalf("reloaded = %v, want [saved]", got)
+	}
+
+	reloaded.record("next", ClipboardSourceApp)
+	if entries := reloaded.search(""); entries[0].ID != 2 {
+		t.Errorf("next ID = %d, want 2", entries[0].ID)
+	}
+}
+
+func TestClipboardHistoryDisabled(t *testing.T) {
+	h, _ := newTestClipboardHistory(t, ClipboardHistoryOptions{Enabled: false})
+	h.record("ignored", ClipboardSourceApp)
+
+	if got := h.search(""); len(got) != 0 {
+		t.Fatalf("entries = %v, want none", got)
+	}
+}
+
+func TestClipboardHistoryOptIn(t *testing.T) {
+	h, _ := newTestClipboardHistory(t, ClipboardHistoryOptions{})
+	h.record("before", ClipboardSourceApp)
+	h.setEnabled(true)
+	h.record("after", ClipboardSourceApp)
+
+	if got := historyTexts(h.search("")); len(got) != 1 || got[0] != "after" {
+		t.Fatalf("entries = %v, want [after]", got)
+	}
+	if clipboardHistoryOptions.Enabled {
+		t.Error("history is recorded by default, want opt-in")
+	}
+}
+
+func TestClipboardHistoryRemembersOptIn(t *testing.T) {
+	h, _ := newTestClipboardHistory(t, ClipboardHistoryOptions{})
+	if err := h.setEnabled(true); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := newClipboardHistory(ClipboardHistoryOptions{}, h.path)
+	if !reloaded.enabled() {
+		t.Fatal("opt-in was lost on reload")
+	}
+
+	if err := reloaded.setEnabled(false); err != nil {
+		t.Fatal(err)
+	}
+	if newClipboardHistory(ClipboardHistoryOptions{Enabled: true}, h.path).enabled() {
+		t.Error("opt-out was lost on reload")
+	}
+}
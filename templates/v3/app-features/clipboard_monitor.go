@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ClipboardChangedEvent is emitted when the clipboard changes
+const ClipboardChangedEvent = "clipboard:changed"
+
+// errClipboardWatchUnsupported makes the monitor fall back to polling
+var errClipboardWatchUnsupported = errors.New("clipboard change notifications unavailable")
+
+// ClipboardChange describes a clipboard change without exposing it by default
+type ClipboardChange struct {
+	// Type is the richest format on offer, e.g. "text/plain" or "image/png"
+	Type string `json:"type"`
+	// Size is the length in bytes of that format's data
+	Size int `json:"size"`
+	// Content is only set for text formats when IncludeContent is enabled
+	Content string `json:"content,omitempty"`
+}
+
+// ClipboardMonitorOptions configures the clipboard monitor
+type ClipboardMonitorOptions struct {
+	// Interval is the polling period when the platform has no change notifications
+	Interval time.Duration
+	// IncludeContent adds text content to change events; sensitive copies are never included
+	IncludeContent bool
+}
+
+// clipboardMonitorOptions can be adjusted before the monitor starts
+var clipboardMonitorOptions = ClipboardMonitorOptions{
+	Interval:       time.Second,
+	IncludeContent: false,
+}
+
+// clipboardMonitor watches for clipboard changes until stopped
+type clipboardMonitor struct {
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	done     chan struct{}
+	lastHash [sha256.Size]byte
+	options  ClipboardMonitorOptions
+	backend  func() (clipboardBackend, error)
+	watch    func(ctx context.Context) (<-chan struct{}, error)
+	readText func() (string, bool)
+	emit     func(ClipboardChange)
+}
+
+var clipboardWatcher = &clipboardMonitor{backend: newClipboardBackend, watch: watchClipboard}
+
+// start begins monitoring; it is a no-op if the monitor is already running
+func (m *clipboardMonitor) start(parent context.Context, readText func() (string, bool), emit func(ClipboardChange)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
+		return
+	}
+	m.options = clipboardMonitorOptions
+	m.readText = readText
+	m.emit = emit
+
+	// The current content is the baseline, not a change
+	kind, data, _ := m.snapshot()
+	m.lastHash = clipboardHash(kind, data)
+
+	ctx, cancel := context.WithCancel(parent)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.run(ctx, m.done)
+}
+
+// stop ends monitoring and waits for the goroutine to exit
+func (m *clipboardMonitor) stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// running reports whether the monitor is active
+func (m *clipboardMonitor) running() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cancel != nil
+}
+
+// run checks the clipboard on every platform notification, or on a timer
+// when notifications are unavailable or stop
+func (m *clipboardMonitor) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	var ticks <-chan time.Time
+	notifications, err := m.watch(ctx)
+	if err != nil {
+		ticker := time.NewTicker(m.options.Interval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-notifications:
+			if !ok {
+				notifications = nil
+				ticker := time.NewTicker(m.options.Interval)
+				defer ticker.Stop()
+				ticks = ticker.C
+				continue
+			}
+			m.check()
+		case <-ticks:
+			m.check()
+		}
+	}
+}
+
+// check emits a change event if the clipboard content hash differs
+func (m *clipboardMonitor) check() {
+	kind, data, ok := m.snapshot()
+	if !ok {
+		return
+	}
+
+	hash := clipboardHash(kind, data)
+	m.mu.Lock()
+	changed := hash != m.lastHash
+	m.lastHash = hash
+	m.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	change := ClipboardChange{Type: kind, Size: len(data)}
+	sensitive := kind == ClipboardFormatText && sensitiveClipboard.matches(string(data))
+	if m.options.IncludeContent && !sensitive && kind != ClipboardFormatPNG {
+		change.Content = string(data)
+	}
+	m.emit(change)
+
+	// History keeps the plain-text alternative of any non-image copy
+	if clipboardHistoryOptions.PollExternal && kind != ClipboardFormatPNG {
+		if text, ok := m.readText(); ok && !sensitiveClipboard.matches(text) {
+			sharedClipboardHistory().recordExternal(text)
+		}
+	}
+}
+
+// snapshot reads the richest available format, falling back to Wails text
+func (m *clipboardMonitor) snapshot() (string, []byte, bool) {
+	if backend, err := m.backend(); err == nil {
+		if formats, err := clipboardFormats(backend); err == nil && len(formats) > 0 {
+			kind := richestClipboardFormat(formats)
+			if data, err := backend.Read(kind); err == nil {
+				return kind, data, true
+			}
+		}
+	}
+
+	text, ok := m.readText()
+	return ClipboardFormatText, []byte(text), ok
+}
+
+// richestClipboardFormat picks the format that best describes the content
+func richestClipboardFormat(formats []string) string {
+	for _, preferred := range []string{ClipboardFormatPNG, ClipboardFormatFiles, ClipboardFormatHTML} {
+		for _, format := range formats {
+			if format == preferred {
+				return format
+			}
+		}
+	}
+	return ClipboardFormatText
+}
+
+// clipboardHash fingerprints content so changes are detected without keeping it
+func clipboardHash(kind string, data []byte) [sha256.Size]byte {
+	return sha256.Sum256(append([]byte(kind+"\x00"), data...))
+}
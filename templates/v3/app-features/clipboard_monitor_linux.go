@@ -0,0 +1,43 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+)
+
+// watchClipboard signals changes via wl-paste --watch on Wayland. X11 has no
+// notification tool, so the monitor polls there.
+func watchClipboard(ctx context.Context) (<-chan struct{}, error) {
+	if os.Getenv("WAYLAND_DISPLAY") == "" || !commandsAvailable("wl-paste") {
+		return nil, errClipboardWatchUnsupported
+	}
+
+	// wl-paste runs echo on every change, giving one line per notification
+	cmd := exec.CommandContext(ctx, "wl-paste", "--watch", "echo")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	notifications := make(chan struct{}, 1)
+	go func() {
+		defer close(notifications)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			// Coalesce bursts; the monitor compares hashes anyway
+			select {
+			case notifications <- struct{}{}:
+			default:
+			}
+		}
+		cmd.Wait()
+	}()
+	return notifications, nil
+}
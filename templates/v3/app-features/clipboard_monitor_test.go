@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// changeRecorder collects emitted clipboard change events
+type changeRecorder struct {
+	mu      sync.Mutex
+	changes []ClipboardChange
+}
+
+func (r *changeRecorder) emit(change ClipboardChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, change)
+}
+
+func (r *changeRecorder) all() []ClipboardChange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ClipboardChange{}, r.changes...)
+}
+
+func newTestClipboardMonitor(backend clipboardBackend, notifications chan struct{}) *clipboardMonitor {
+	return &clipboardMonitor{
+		backend: func() (clipboardBackend, error) {
+			if backend == nil {
+				return nil, ErrClipboardFormatUnsupported
+			}
+			return backend, nil
+		},
+		watch: func(ctx context.Context) (<-chan struct{}, error) {
+			if notifications == nil {
+				return nil, errClipboardWatchUnsupported
+			}
+			return notifications, nil
+		},
+	}
+}
+
+func TestClipboardMonitorEmitsOnlyOnChange(t *testing.T) {
+	clipboard := &fakeTextClipboard{text: "baseline"}
+	recorder := &changeRecorder{}
+	notifications := make(chan struct{})
+	monitor := newTestClipboardMonitor(nil, notifications)
+
+	monitor.start(context.Background(), clipboard.read, recorder.emit)
+	defer monitor.stop()
+
+	notifications <- struct{}{}
+	clipboard.write("hello")
+	notifications <- struct{}{}
+	notifications <- struct{}{}
+	monitor.stop()
+
+	changes := recorder.all()
+	if len(changes) != 1 {
+		t.Fatalf("changes = %+v, want exactly one", changes)
+	}
+	if changes[0].Type != ClipboardFormatText || changes[0].Size != 5 || changes[0].Content != "" {
+		t.Errorf("change = %+v, want 5 bytes of text without content", changes[0])
+	}
+}
+
+func TestClipboardMonitorPollsWithoutNotifications(t *testing.T) {
+	saved := clipboardMonitorOptions
+	clipboardMonitorOptions.Interval = 5 * time.Millisecond
+	defer func() { clipboardMonitorOptions = saved }()
+
+	clipboard := &fakeTextClipboard{}
+	recorder := &changeRecorder{}
+	monitor := newTestClipboardMonitor(nil, nil)
+
+	monitor.start(context.Background(), clipboard.read, recorder.emit)
+	if !monitor.running() {
+		t.Fatal("running() = false after start")
+	}
+	clipboard.write("polled")
+
+	deadline := time.Now().Add(time.Second)
+	for len(recorder.all()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	monitor.stop()
+
+	if monitor.running() {
+		t.Error("running() = true after stop")
+	}
+	if len(recorder.all()) != 1 {
+		t.Errorf("changes = %+v, want one polled change", recorder.all())
+	}
+}
+
+func TestClipboardMonitorContentOptIn(t *testing.T) {
+	saved := clipboardMonitorOptions
+	clipboardMonitorOptions.IncludeContent = true
+	defer func() { clipboardMonitorOptions = saved }()
+
+	clipboard := &fakeTextClipboard{}
+	recorder := &changeRecorder{}
+	notifications := make(chan struct{})
+	monitor := newTestClipboardMonitor(nil, notifications)
+
+	monitor.start(context.Background(), clipboard.read, recorder.emit)
+	clipboard.write("https://example.com")
+	notifications <- struct{}{}
+
+	// Sensitive copies never expose their content
+	savedConceal := sensitiveClipboard.conceal
+	sensitiveClipboard.conceal = func(string) error { return ErrClipboardFormatUnsupported }
+	defer func() {
+		sensitiveClipboard.mu.Lock()
+		sensitiveClipboard.timer.Stop()
+		sensitiveClipboard.active = false
+		sensitiveClipboard.conceal = savedConceal
+		sensitiveClipboard.mu.Unlock()
+	}()
+	sensitiveClipboard.copySecret("hunter2", time.Hour, clipboard.write, clipboard.read)
+	notifications <- struct{}{}
+	monitor.stop()
+
+	changes := recorder.all()
+	if len(changes) != 2 {
+		t.Fatalf("changes = %+v, want two", changes)
+	}
+	if changes[0].Content != "https://example.com" {
+		t.Errorf("content = %q, want the copied URL", changes[0].Content)
+	}
+	if changes[1].Content != "" || changes[1].Size != len("hunter2") {
+		t.Errorf("sensitive change = %+v, want size only", changes[1])
+	}
+}
+
+func TestClipboardMonitorReportsRichestFormat(t *testing.T) {
+	backend := newFakeClipboard()
+	clipboard := &fakeTextClipboard{}
+	recorder := &changeRecorder{}
+	notifications := make(chan struct{})
+	monitor := newTestClipboardMonitor(backend, notifications)
+
+	monitor.start(context.Background(), clipboard.read, recorder.emit)
+	backend.data = map[string][]byte{
+		"text/plain": []byte("a"),
+		"text/html":  []byte("<b>a</b>"),
+	}
+	notifications <- struct{}{}
+	monitor.stop()
+
+	changes := recorder.all()
+	if len(changes) != 1 || changes[0].Type != ClipboardFormatHTML || changes[0].Size != len("<b>a</b>") {
+		t.Errorf("changes = %+v, want one text/html change", changes)
+	}
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"time"
+)
+
+var procGetClipboardSequenceNumber = clipboardUser32.NewProc("GetClipboardSequenceNumber")
+
+// watchClipboard signals changes to the clipboard sequence number, which is
+// cheap to check and avoids reading the content until it actually changes
+func watchClipboard(ctx context.Context) (<-chan struct{}, error) {
+	if err := procGetClipboardSequenceNumber.Find(); err != nil {
+		return nil, errClipboardWatchUnsupported
+	}
+
+	notifications := make(chan struct{}, 1)
+	go func() {
+		defer close(notifications)
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+
+		last, _, _ := procGetClipboardSequenceNumber.Call()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sequence, _, _ := procGetClipboardSequenceNumber.Call()
+				if sequence == last {
+					continue
+				}
+				last = sequence
+				select {
+				case notifications <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return notifications, nil
+}
@@ -0,0 +1,11 @@
+//go:build !windows && !darwin
+
+package main
+
+// writeConcealedText cannot conceal anything here: wl-copy and xclip publish a
+// single target per run, so the KDE password-manager hint cannot accompany the
+// text. The caller falls back to a plain copy, which is still cleared after
+// its TTL and kept out of the history.
+func writeConcealedText(text string) error {
+	return ErrClipboardFormatUnsupported
+}
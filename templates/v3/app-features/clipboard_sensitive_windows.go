@@ -0,0 +1,113 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"runtime"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	clipboardUser32              = windows.NewLazySystemDLL("user32.dll")
+	clipboardKernel32            = windows.NewLazySystemDLL("kernel32.dll")
+	procOpenClipboard            = clipboardUser32.NewProc("OpenClipboard")
+	procCloseClipboard           = clipboardUser32.NewProc("CloseClipboard")
+	procEmptyClipboard           = clipboardUser32.NewProc("EmptyClipboard")
+	procSetClipboardData         = clipboardUser32.NewProc("SetClipboardData")
+	procRegisterClipboardFormatW = clipboardUser32.NewProc("RegisterClipboardFormatW")
+	procGlobalAlloc              = clipboardKernel32.NewProc("GlobalAlloc")
+	procGlobalLock               = clipboardKernel32.NewProc("GlobalLock")
+	procGlobalUnlock             = clipboardKernel32.NewProc("GlobalUnlock")
+	procGlobalFree               = clipboardKernel32.NewProc("GlobalFree")
+	procRtlMoveMemory            = clipboardKernel32.NewProc("RtlMoveMemory")
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+// windowsConcealFormats keep the copy out of Win+V history, cloud sync and
+// clipboard monitors
+var windowsConcealFormats = []string{
+	"ExcludeClipboardContentFromMonitorProcessing",
+	"CanIncludeInClipboardHistory",
+	"CanUploadToCloudClipboard",
+}
+
+// writeConcealedText copies text together with the Windows exclusion formats
+func writeConcealedText(text string) error {
+	encoded, err := windows.UTF16FromString(text)
+	if err != nil {
+		return err
+	}
+
+	// Clipboard ownership is tied to the calling thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := openClipboard(); err != nil {
+		return err
+	}
+	defer procCloseClipboard.Call()
+
+	if r, _, err := procEmptyClipboard.Call(); r == 0 {
+		return err
+	}
+	textBytes := unsafe.Slice((*byte)(unsafe.Pointer(&encoded[0])), len(encoded)*2)
+	if err := setClipboardData(cfUnicodeText, textBytes); err != nil {
+		return err
+	}
+
+	// A DWORD 0 means "no" for the Can* formats; the Exclude format only needs to exist
+	for _, name := range windowsConcealFormats {
+		namePtr, err := windows.UTF16PtrFromString(name)
+		if err != nil {
+			return err
+		}
+		format, _, err := procRegisterClipboardFormatW.Call(uintptr(unsafe.Pointer(namePtr)))
+		if format == 0 {
+			return err
+		}
+		if err := setClipboardData(format, []byte{0, 0, 0, 0}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openClipboard retries briefly since another app may hold the clipboard
+func openClipboard() error {
+	for attempt := 0; attempt < 5; attempt++ {
+		if r, _, _ := procOpenClipboard.Call(0); r != 0 {
+			return nil
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return errors.New("clipboard is in use by another application")
+}
+
+// setClipboardData copies data into a global memory block owned by the clipboard
+func setClipboardData(format uintptr, data []byte) error {
+	handle, _, err := procGlobalAlloc.Call(gmemMoveable, uintptr(len(data)))
+	if handle == 0 {
+		return err
+	}
+	ptr, _, err := procGlobalLock.Call(handle)
+	if ptr == 0 {
+		procGlobalFree.Call(handle)
+		return err
+	}
+	procRtlMoveMemory.Call(ptr, uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)))
+	procGlobalUnlock.Call(handle)
+
+	if r, _, err := procSetClipboardData.Call(format, handle); r == 0 {
+		procGlobalFree.Call(handle)
+		return err
+	}
+	return nil
+}
@@ -1,9 +1,9 @@
 // App Config Helper
-import { LoadConfig, SaveConfig, GetSetting, SetSetting } from '../wailsjs/go/main/App'
+import { ConfigService } from '../bindings/changeme'
 
 export async function loadConfig() {
   try {
-    const config = await LoadConfig()
+    const config = await ConfigService.LoadConfig()
     return config
   } catch (error) {
     console.error('Failed to load config:', error)
@@ -13,7 +13,7 @@ export async function loadConfig() {
 
 export async function saveConfig(config) {
   try {
-    await SaveConfig(config)
+    await ConfigService.SaveConfig(config)
     console.log('Config saved successfully')
     return true
   } catch (error) {
@@ -24,7 +24,7 @@ export async function saveConfig(config) {
 
 export async function getSetting(key) {
   try {
-    return await GetSetting(key)
+    return await ConfigService.GetSetting(key)
   } catch (error) {
     console.error('Failed to get setting:', error)
     return null
@@ -33,7 +33,7 @@ export async function getSetting(key) {
 
 export async function setSetting(key, value) {
   try {
-    await SetSetting(key, value)
+    await ConfigService.SetSetting(key, value)
     return true
   } catch (error) {
     console.error('Failed to set setting:', error)
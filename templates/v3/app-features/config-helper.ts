@@ -1,5 +1,5 @@
 // App Config Helper
-import { LoadConfig, SaveConfig, GetSetting, SetSetting } from '../wailsjs/go/main/App'
+import { ConfigService } from '../bindings/changeme'
 
 interface AppConfig {
   theme: string
@@ -11,7 +11,7 @@ interface AppConfig {
 
 export async function loadConfig(): Promise<AppConfig | null> {
   try {
-    const config = await LoadConfig()
+    const config = await ConfigService.LoadConfig()
     return config
   } catch (error) {
     console.error('Failed to load config:', error)
@@ -21,7 +21,7 @@ export async function loadConfig(): Promise<AppConfig | null> {
 
 export async function saveConfig(config: AppConfig) {
   try {
-    await SaveConfig(config)
+    await ConfigService.SaveConfig(config)
     console.log('Config saved successfully')
     return true
   } catch (error) {
@@ -32,7 +32,7 @@ export async function saveConfig(config: AppConfig) {
 
 export async function getSetting(key: string) {
   try {
-    return await GetSetting(key)
+    return await ConfigService.GetSetting(key)
   } catch (error) {
     console.error('Failed to get setting:', error)
     return null
@@ -41,7 +41,7 @@ export async function getSetting(key: string) {
 
 export async function setSetting(key: string, value: any) {
   try {
-    await SetSetting(key, value)
+    await ConfigService.SetSetting(key, value)
     return true
   } catch (error) {
     console.error('Failed to set setting:', error)
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
 )
 
 // AppConfig represents the application configuration
@@ -15,8 +18,22 @@ type AppConfig struct {
 	CustomSettings map[string]interface{} `json:"customSettings"`
 }
 
+// ConfigService loads and saves the application configuration
+type ConfigService struct{}
+
+// ServiceStartup creates the config directory so the first save cannot fail on it
+func (c *ConfigService) ServiceStartup(ctx context.Context, options application.ServiceOptions) error {
+	_, err := c.GetConfigPath()
+	return err
+}
+
+// ServiceShutdown has nothing to release; settings are written on every save
+func (c *ConfigService) ServiceShutdown() error {
+	return nil
+}
+
 // GetConfigPath returns the path to the config file
-func (a *App) GetConfigPath() (string, error) {
+func (c *ConfigService) GetConfigPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -32,15 +49,15 @@ func (a *App) GetConfigPath() (string, error) {
 }
 
 // LoadConfig loads the application configuration
-func (a *App) LoadConfig() (*AppConfig, error) {
-	configPath, err := a.GetConfigPath()
+func (c *ConfigService) LoadConfig() (*AppConfig, error) {
+	configPath, err := c.GetConfigPath()
 	if err != nil {
 		return nil, err
 	}
 
 	// Return default config if file doesn't exist
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return a.GetDefaultConfig(), nil
+		return c.GetDefaultConfig(), nil
 	}
 
 	data, err := os.ReadFile(configPath)
@@ -58,8 +75,8 @@ func (a *App) LoadConfig() (*AppConfig, error) {
 }
 
 // SaveConfig saves the application configuration
-func (a *App) SaveConfig(config *AppConfig) error {
-	configPath, err := a.GetConfigPath()
+func (c *ConfigService) SaveConfig(config *AppConfig) error {
+	configPath, err := c.GetConfigPath()
 	if err != nil {
 		return err
 	}
@@ -73,7 +90,7 @@ func (a *App) SaveConfig(config *AppConfig) error {
 }
 
 // GetDefaultConfig returns the default configuration
-func (a *App) GetDefaultConfig() *AppConfig {
+func (c *ConfigService) GetDefaultConfig() *AppConfig {
 	return &AppConfig{
 		Theme:        "light",
 		Language:     "en",
@@ -84,8 +101,8 @@ func (a *App) GetDefaultConfig() *AppConfig {
 }
 
 // GetSetting gets a specific setting value
-func (a *App) GetSetting(key string) (interface{}, error) {
-	config, err := a.LoadConfig()
+func (c *ConfigService) GetSetting(key string) (interface{}, error) {
+	config, err := c.LoadConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -98,8 +115,8 @@ func (a *App) GetSetting(key string) (interface{}, error) {
 }
 
 // SetSetting sets a specific setting value
-func (a *App) SetSetting(key string, value interface{}) error {
-	config, err := a.LoadConfig()
+func (c *ConfigService) SetSetting(key string, value interface{}) error {
+	config, err := c.LoadConfig()
 	if err != nil {
 		return err
 	}
@@ -109,5 +126,5 @@ func (a *App) SetSetting(key string, value interface{}) error {
 	}
 
 	config.CustomSettings[key] = value
-	return a.SaveConfig(config)
+	return c.SaveConfig(config)
 }
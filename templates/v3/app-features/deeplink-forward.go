@@ -0,0 +1,15 @@
+package main
+
+import "fmt"
+
+// Route deep links and opened files from a second launch through the running instance
+func init() {
+	secondInstanceHooks = append(secondInstanceHooks, func(msg SecondInstanceMessage) {
+		for _, link := range deepLinkArgs(msg.Args) {
+			if err := dispatchDeepLink(link); err != nil {
+				fmt.Println(err)
+			}
+		}
+		openFiles(fileAssociationArgs(msg.Args, msg.WorkingDir))
+	})
+}
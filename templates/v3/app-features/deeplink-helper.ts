@@ -0,0 +1,65 @@
+// Deep Link Helper
+import { DeepLinkService } from '../bindings/changeme'
+import { Events } from '@wailsio/runtime'
+
+interface DeepLink {
+  id: number
+  url: string
+  host: string
+  path: string
+  params: Record<string, string>
+  route?: string
+}
+
+type DeepLinkListener = (link: DeepLink) => void | Promise<void>
+
+const seen = new Set<number>()
+
+async function deliver(link: DeepLink, listener: DeepLinkListener) {
+  // A link can arrive both as an event and from DeepLinkReady after a reload
+  if (seen.has(link.id)) {
+    return
+  }
+  seen.add(link.id)
+
+  try {
+    await listener(link)
+  } finally {
+    await DeepLinkService.AckDeepLinks([link.id])
+  }
+}
+
+// Subscribe to deep links, including those received before the page loaded
+export async function onDeepLink(listener: DeepLinkListener) {
+  Events.On('deeplink', (event) => deliver(event.data as DeepLink, listener))
+
+  try {
+    const queued = await DeepLinkService.DeepLinkReady()
+    for (const link of queued || []) {
+      await deliver(link, listener)
+    }
+  } catch (error) {
+    console.error('Failed to fetch queued deep links:', error)
+  }
+}
+
+// Subscribe to documents opened from the file manager or a second launch.
+// Call this before onDeepLink so files from a cold start are not missed.
+export function onOpenFiles(listener: (paths: string[]) => void) {
+  Events.On('OpenFiles', (event) => listener(event.data as string[]))
+}
+
+// Example usage
+export async function setupDeepLinks() {
+  onOpenFiles((paths) => {
+    console.log('Open documents:', paths)
+  })
+
+  await onDeepLink((link) => {
+    if (link.host === 'auth') {
+      console.log('Auth callback code:', link.params.code)
+    } else {
+      console.log('Navigate to:', link.path)
+    }
+  })
+}
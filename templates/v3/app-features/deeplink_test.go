@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDeepLinkRouterTriesOverlappingRoutes(t *testing.T) {
+	r := NewDeepLinkRouter("testapp")
+	var matched string
+	for _, pattern := range []string{"testapp://open/{id:int}", "testapp://open/{slug:slug}"} {
+		pattern := pattern
+		if err := r.Handle(pattern, func(link *DeepLink) error {
+			matched = pattern
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		url, route, param, value string
+	}{
+		{"testapp://open/42", "testapp://open/{id:int}", "id", "42"},
+		{"testapp://open/release-notes", "testapp://open/{slug:slug}", "slug", "release-notes"},
+	}
+	for _, test := range tests {
+		link, handled, err := r.Route(test.url)
+		if err != nil || !handled {
+			t.Fatalf("Route(%q) = handled %v, %v", test.url, handled, err)
+		}
+		if matched != test.route || link.Route != test.route || link.Params[test.param] != test.value {
+			t.Errorf("Route(%q) matched %q with params %v, want %q", test.url, matched, link.Params, test.route)
+		}
+	}
+
+	// Neither route fits, so the parameter error is reported
+	if _, _, err := r.Route("testapp://open/a.b"); !errors.Is(err, ErrDeepLinkParamFormat) {
+		t.Errorf("Route(no fit) error = %v, want ErrDeepLinkParamFormat", err)
+	}
+}
+
+func TestDeepLinkRouterSkipsRouteMissingQuery(t *testing.T) {
+	r := NewDeepLinkRouter("testapp")
+	if err := r.Handle("testapp://auth/callback?code=&state=", func(*DeepLink) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Forward("testapp://auth/callback?error="); err != nil {
+		t.Fatal(err)
+	}
+
+	link, handled, err := r.Route("testapp://auth/callback?error=denied")
+	if err != nil || handled || link.Route != "testapp://auth/callback?error=" {
+		t.Errorf("Route(error callback) = %+v, handled %v, %v; want it forwarded by the second route", link, handled, err)
+	}
+
+	if _, _, err := r.Route("testapp://auth/callback?code=1"); !errors.Is(err, ErrDeepLinkParamFormat) {
+		t.Errorf("Route(missing state) error = %v, want ErrDeepLinkParamFormat", err)
+	}
+}
+
+func TestDeepLinkRouterRejects(t *testing.T) {
+	r := NewDeepLinkRouter("testapp")
+	for _, pattern := range []string{"testapp://item/{id:int}", "testapp://tag/{name:slug}", "testapp://user/{id:uuid}"} {
+		if err := r.Handle(pattern, func(*DeepLink) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.AllowHost("view")
+
+	tests := []struct {
+		name, url string
+		want      error
+	}{
+		{"int letters", "testapp://item/abc", ErrDeepLinkParamFormat},
+		{"int too long", "testapp://item/1234567890123456789", ErrDeepLinkParamFormat},
+		{"slug dot", "testapp://tag/a.b", ErrDeepLinkParamFormat},
+		{"uuid short", "testapp://user/123e4567-e89b-12d3-a456", ErrDeepLinkParamFormat},
+		{"unknown host", "testapp://admin/1", ErrDeepLinkHost},
+		{"other scheme", "other://item/1", ErrDeepLinkScheme},
+		{"too long", "testapp://view/" + strings.Repeat("a", MaxDeepLinkLength), ErrDeepLinkTooLong},
+		{"encoded slash", "testapp://view/a%2Fb", ErrDeepLinkMalformed},
+		{"encoded backslash", "testapp://view/a%5Cb", ErrDeepLinkMalformed},
+		{"encoded dot dot", "testapp://view/%2E%2E/secret", ErrDeepLinkMalformed},
+		{"space", "testapp://view/a b", ErrDeepLinkMalformed},
+		{"user info", "testapp://me@view/1", ErrDeepLinkMalformed},
+		{"port", "testapp://view:8080/1", ErrDeepLinkMalformed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := r.Route(test.url); !errors.Is(err, test.want) {
+				t.Errorf("Route(%q) error = %v, want %v", test.url, err, test.want)
+			}
+		})
+	}
+
+	link, handled, err := r.Route("testapp://user/123E4567-E89B-12D3-A456-426614174000")
+	if err != nil || !handled || link.Params["id"] != "123E4567-E89B-12D3-A456-426614174000" {
+		t.Errorf("Route(uuid) = %+v, handled %v, %v", link, handled, err)
+	}
+	link, handled, err = r.Route("testapp://view/a%20b/c")
+	if err != nil || handled || link.Path != "/a b/c" {
+		t.Errorf("Route(encoded space) = %+v, handled %v, %v; want it forwarded with path /a b/c", link, handled, err)
+	}
+}
+
+func TestDeepLinkQueueHoldsUntilReadyAndAck(t *testing.T) {
+	q := &deepLinkQueue{}
+
+	// Links arriving before the frontend listens are held, not emitted
+	if q.push(&DeepLink{URL: "testapp://view/1"}) {
+		t.Fatal("push before ready reported ready")
+	}
+	if q.pushFiles([]string{"/tmp/a.testapp"}) {
+		t.Fatal("pushFiles before ready reported ready")
+	}
+
+	pending := q.markReady()
+	if len(pending) != 1 || pending[0].URL != "testapp://view/1" || pending[0].ID == 0 {
+		t.Fatalf("markReady = %+v, want the queued link with an ID", pending)
+	}
+	if files := q.takeFiles(); len(files) != 1 || files[0] != "/tmp/a.testapp" {
+		t.Errorf("takeFiles = %v, want the buffered file", files)
+	}
+
+	// Once ready, new links are emitted directly but stay pending until acknowledged
+	if !q.push(&DeepLink{URL: "testapp://view/2"}) {
+		t.Error("push after ready reported not ready")
+	}
+	if !q.pushFiles([]string{"/tmp/b.testapp"}) || len(q.takeFiles()) != 0 {
+		t.Error("pushFiles after ready buffered the files instead of emitting them")
+	}
+
+	// A reloaded frontend gets only the links it has not acknowledged
+	q.ack([]uint64{pending[0].ID})
+	if again := q.markReady(); len(again) != 1 || again[0].URL != "testapp://view/2" {
+		t.Fatalf("markReady after ack = %+v, want only the unacknowledged link", again)
+	}
+	q.ack([]uint64{pending[0].ID + 1})
+	if again := q.markReady(); len(again) != 0 {
+		t.Errorf("markReady after acking everything = %+v, want none", again)
+	}
+}
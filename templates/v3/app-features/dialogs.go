@@ -1,90 +1,243 @@
 package main
 
 import (
+	"context"
+	"runtime"
+
 	"github.com/wailsapp/wails/v3/pkg/application"
 )
 
+// DialogService exposes native file and message dialogs to the frontend
+type DialogService struct {
+	app *application.App
+	ctx context.Context
+}
+
+// ServiceStartup keeps a handle to the running application
+func (d *DialogService) ServiceStartup(ctx context.Context, options application.ServiceOptions) error {
+	d.app = application.Get()
+	d.ctx = ctx
+	return nil
+}
+
+// ServiceShutdown is a no-op
+func (d *DialogService) ServiceShutdown() error {
+	return nil
+}
+
 // OpenFileDialog opens a native file picker dialog
-func (a *App) OpenFileDialog() (string, error) {
-	file, err := a.app.Dialog.OpenFile(&application.FileDialogOptions{
-		Title: "Select File",
-		Filters: []application.FileFilter{
-			{
-				DisplayName: "All Files (*.*)",
-				Pattern:     "*.*",
-			},
-		},
+func (d *DialogService) OpenFileDialog() (string, error) {
+	return d.OpenFileDialogWithOptions(FileDialogOptions{
+		Title:   "Select File",
+		Filters: []DialogFilter{allFilesFilter},
 	})
+}
+
+// OpenFileDialogWithOptions opens a native file picker configured by the frontend
+func (d *DialogService) OpenFileDialogWithOptions(options FileDialogOptions) (string, error) {
+	dialogOptions, err := openFileDialogOptions(options)
+	if err != nil {
+		return "", err
+	}
+	dialogOptions.CanChooseFiles = true
+	file, err := d.app.Dialog.OpenFileWithOptions(dialogOptions).PromptForSingleSelection()
+	if err == nil {
+		sharedDialogState().remember(options.Purpose, []string{file}, false, true)
+	}
 	return file, err
 }
 
 // OpenMultipleFilesDialog opens a native file picker for multiple files
-func (a *App) OpenMultipleFilesDialog() ([]string, error) {
-	files, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select Files",
-		Filters: []runtime.FileFilter{
-			{
-				DisplayName: "All Files (*.*)",
-				Pattern:     "*.*",
-			},
-		},
+func (d *DialogService) OpenMultipleFilesDialog() ([]string, error) {
+	return d.OpenMultipleFilesDialogWithOptions(FileDialogOptions{
+		Title:   "Select Files",
+		Filters: []DialogFilter{allFilesFilter},
 	})
+}
+
+// OpenMultipleFilesDialogWithOptions opens a native multi-file picker configured by the frontend
+func (d *DialogService) OpenMultipleFilesDialogWithOptions(options FileDialogOptions) ([]string, error) {
+	dialogOptions, err := openFileDialogOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	dialogOptions.CanChooseFiles = true
+	dialogOptions.AllowsMultipleSelection = true
+	files, err := d.app.Dialog.OpenFileWithOptions(dialogOptions).PromptForMultipleSelection()
+	if err == nil {
+		sharedDialogState().remember(options.Purpose, files, false, true)
+	}
 	return files, err
 }
 
 // OpenDirectoryDialog opens a native directory picker dialog
-func (a *App) OpenDirectoryDialog() (string, error) {
-	directory, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+func (d *DialogService) OpenDirectoryDialog() (string, error) {
+	return d.OpenDirectoryDialogWithOptions(FileDialogOptions{
 		Title: "Select Directory",
 	})
+}
+
+// OpenDirectoryDialogWithOptions opens a native directory picker configured by
+// the frontend; filters do not apply
+func (d *DialogService) OpenDirectoryDialogWithOptions(options FileDialogOptions) (string, error) {
+	dialogOptions, err := openFileDialogOptions(options)
+	if err != nil {
+		return "", err
+	}
+	dialogOptions.CanChooseDirectories = true
+	dialogOptions.Filters = nil
+	directory, err := d.app.Dialog.OpenFileWithOptions(dialogOptions).PromptForSingleSelection()
+	if err == nil {
+		sharedDialogState().remember(options.Purpose, []string{directory}, true, false)
+	}
 	return directory, err
 }
 
 // SaveFileDialog opens a native save file dialog
-func (a *App) SaveFileDialog() (string, error) {
-	file, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+func (d *DialogService) SaveFileDialog() (string, error) {
+	return d.SaveFileDialogWithOptions(FileDialogOptions{
 		Title:           "Save File",
 		DefaultFilename: "untitled.txt",
-		Filters: []runtime.FileFilter{
-			{
-				DisplayName: "Text Files (*.txt)",
-				Pattern:     "*.txt",
-			},
-			{
-				DisplayName: "All Files (*.*)",
-				Pattern:     "*.*",
-			},
+		Filters: []DialogFilter{
+			{DisplayName: "Text Files (*.txt)", Pattern: "*.txt"},
+			allFilesFilter,
 		},
 	})
+}
+
+// SaveFileDialogWithOptions opens a native save file dialog configured by the frontend
+func (d *DialogService) SaveFileDialogWithOptions(options FileDialogOptions) (string, error) {
+	options, err := sharedDialogState().prepare(options)
+	if err != nil {
+		return "", err
+	}
+	file, err := d.app.Dialog.SaveFileWithOptions(&application.SaveFileDialogOptions{
+		Title:                options.Title,
+		Directory:            options.DefaultDirectory,
+		Filename:             options.DefaultFilename,
+		Filters:              applicationFileFilters(options.Filters),
+		ShowHiddenFiles:      options.ShowHiddenFiles,
+		CanCreateDirectories: options.CanCreateDirectories,
+	}).PromptForSingleSelection()
+	if err == nil {
+		sharedDialogState().remember(options.Purpose, []string{file}, false, false)
+	}
 	return file, err
 }
 
-// ShowInfoDialog shows an information message dialog
-func (a *App) ShowInfoDialog(title, message string) {
-	runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-		Type:    runtime.InfoDialog,
-		Title:   title,
-		Message: message,
+// GetRecentFiles returns files recently chosen in open dialogs that still exist, newest first
+func (d *DialogService) GetRecentFiles() []string {
+	return sharedDialogState().recentFiles()
+}
+
+// ClearRecentFiles empties the recent-files list
+func (d *DialogService) ClearRecentFiles() error {
+	return sharedDialogState().clearRecentFiles()
+}
+
+// openFileDialogOptions maps validated options, starting in the directory
+// remembered for their purpose, onto the application's open dialog options;
+// the caller chooses files, directories or both
+func openFileDialogOptions(options FileDialogOptions) (*application.OpenFileDialogOptions, error) {
+	options, err := sharedDialogState().prepare(options)
+	if err != nil {
+		return nil, err
+	}
+	return &application.OpenFileDialogOptions{
+		Title:                options.Title,
+		Directory:            options.DefaultDirectory,
+		Filters:              applicationFileFilters(options.Filters),
+		ShowHiddenFiles:      options.ShowHiddenFiles,
+		CanCreateDirectories: options.CanCreateDirectories,
+		ResolvesAliases:      options.ResolvesAliases,
+	}, nil
+}
+
+// applicationFileFilters converts filters to the application's type
+func applicationFileFilters(filters []DialogFilter) []application.FileFilter {
+	converted := make([]application.FileFilter, 0, len(filters))
+	for _, filter := range filters {
+		converted = append(converted, application.FileFilter{DisplayName: filter.DisplayName, Pattern: filter.Pattern})
+	}
+	return converted
+}
+
+// ShowMessage shows a message dialog with custom buttons and returns which
+// one was chosen. With a DontAskAgain key the choice can be remembered, after
+// which the dialog is skipped; ResetDontAskAgain shows it again.
+func (d *DialogService) ShowMessage(options MessageOptions) (MessageResult, error) {
+	return sharedDialogState().showMessage(options, func(options MessageOptions) (string, bool, error) {
+		if label, dontAskAgain, ok, err := nativeMessageDialog(options); ok {
+			return label, dontAskAgain, err
+		}
+
+		var dialog *application.MessageDialog
+		switch options.Type {
+		case MessageTypeWarning:
+			dialog = d.app.Dialog.Warning()
+		case MessageTypeError:
+			dialog = d.app.Dialog.Error()
+		case MessageTypeQuestion:
+			dialog = d.app.Dialog.Question()
+		default:
+			dialog = d.app.Dialog.Info()
+		}
+		dialog.SetTitle(options.Title).SetMessage(options.Message)
+		if len(options.icon) > 0 {
+			dialog.SetIcon(options.icon)
+		}
+
+		// Button callbacks only run for a click, so dismissing the dialog sends nothing
+		choice := make(chan string, 1)
+		for _, label := range options.Buttons {
+			button := dialog.AddButton(label).OnClick(func() { choice <- label })
+			switch label {
+			case options.DefaultButton:
+				dialog.SetDefaultButton(button)
+			case options.CancelButton:
+				dialog.SetCancelButton(button)
+			}
+		}
+		dialog.Show()
+
+		// Windows shows the dialog synchronously, so no choice means it was
+		// dismissed; elsewhere it is shown asynchronously
+		var label string
+		if runtime.GOOS == "windows" {
+			select {
+			case label = <-choice:
+			default:
+			}
+		} else {
+			select {
+			case label = <-choice:
+			case <-d.ctx.Done():
+			}
+		}
+		return label, false, nil
 	})
 }
 
+// ResetDontAskAgain shows a message again after "don't ask again" was chosen
+// for its key; an empty key resets every message
+func (d *DialogService) ResetDontAskAgain(key string) error {
+	return sharedDialogState().forgetChoices(key)
+}
+
+// ShowInfoDialog shows an information message dialog
+func (d *DialogService) ShowInfoDialog(title, message string) error {
+	_, err := d.ShowMessage(MessageOptions{Type: MessageTypeInfo, Title: title, Message: message})
+	return err
+}
+
 // ShowErrorDialog shows an error message dialog
-func (a *App) ShowErrorDialog(title, message string) {
-	runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-		Type:    runtime.ErrorDialog,
-		Title:   title,
-		Message: message,
-	})
+func (d *DialogService) ShowErrorDialog(title, message string) error {
+	_, err := d.ShowMessage(MessageOptions{Type: MessageTypeError, Title: title, Message: message})
+	return err
 }
 
-// ShowQuestionDialog shows a question dialog and returns the user's choice
-func (a *App) ShowQuestionDialog(title, message string) (string, error) {
-	result, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-		Type:          runtime.QuestionDialog,
-		Title:         title,
-		Message:       message,
-		Buttons:       []string{"Yes", "No"},
-		DefaultButton: "Yes",
-	})
-	return result, err
+// ShowQuestionDialog shows a Yes/No question dialog and returns the user's choice
+func (d *DialogService) ShowQuestionDialog(title, message string) (string, error) {
+	result, err := d.ShowMessage(MessageOptions{Type: MessageTypeQuestion, Title: title, Message: message})
+	return result.Button, err
 }
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// nativeMessageDialog reports false so the runtime's message dialog is used
+func nativeMessageDialog(options MessageOptions) (string, bool, bool, error) {
+	return "", false, false, nil
+}
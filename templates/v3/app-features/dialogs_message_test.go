@@ -0,0 +1,155 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMessageOptionsNormalizeDefaults(t *testing.T) {
+	options, err := MessageOptions{Type: MessageTypeQuestion, Icon: "data:image/png;base64,iVBORw0K"}.normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(options.Buttons, []string{"Yes", "No"}) || options.DefaultButton != "Yes" || options.CancelButton != "No" {
+		t.Errorf("question defaults = %v default %q cancel %q", options.Buttons, options.DefaultButton, options.CancelButton)
+	}
+	if string(options.icon) != "\x89PNG\r\n" {
+		t.Errorf("icon = %q, want the decoded PNG header", options.icon)
+	}
+
+	options, err = MessageOptions{}.normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Type != MessageTypeInfo || !reflect.DeepEqual(options.Buttons, []string{"OK"}) {
+		t.Errorf("info defaults = %q %v", options.Type, options.Buttons)
+	}
+
+	// The checkbox does not take a button's place
+	options, err = MessageOptions{Buttons: []string{"a", "b", "c", "d"}, DontAskAgain: "key"}.normalize()
+	if err != nil || options.DontAskAgainLabel != defaultDontAskAgainLabel {
+		t.Errorf("four buttons with dontAskAgain = label %q, %v", options.DontAskAgainLabel, err)
+	}
+}
+
+func TestMessageOptionsNormalizeRejects(t *testing.T) {
+	tests := map[string]MessageOptions{
+		"unknown type":      {Type: "fatal"},
+		"too many buttons":  {Buttons: []string{"a", "b", "c", "d", "e"}},
+		"duplicate button":  {Buttons: []string{"Save", "Save"}},
+		"empty button":      {Buttons: []string{"Save", " "}},
+		"unknown default":   {Buttons: []string{"Save"}, DefaultButton: "Discard"},
+		"unknown cancel":    {Buttons: []string{"Save"}, CancelButton: "Cancel"},
+		"invalid key":       {DontAskAgain: "../x"},
+		"invalid icon data": {Icon: "not base64!"},
+	}
+	for name, options := range tests {
+		if _, err := options.normalize(); err == nil {
+			t.Errorf("%s: normalize accepted %+v", name, options)
+		}
+	}
+}
+
+func TestMessageOptionsResolve(t *testing.T) {
+	options, _ := MessageOptions{
+		Buttons:       []string{"Save", "Don't Save", "Cancel"},
+		DefaultButton: "Save",
+		CancelButton:  "Cancel",
+	}.normalize()
+
+	tests := []struct {
+		label   string
+		button  string
+		clicked bool
+	}{
+		{"Don't Save", "Don't Save", true},
+		{"save", "Save", true},
+		// Windows and GTK message boxes answer with their own buttons
+		{"Yes", "Save", true},
+		{"Ok", "Save", true},
+		{"No", "Cancel", true},
+		{"", "Cancel", false},
+	}
+	for _, test := range tests {
+		button, clicked := options.resolve(test.label)
+		if button != test.button || clicked != test.clicked {
+			t.Errorf("resolve(%q) = %q, %v; want %q, %v", test.label, button, clicked, test.button, test.clicked)
+		}
+	}
+}
+
+func TestShowMessageRemembersChoice(t *testing.T) {
+	state := newDialogState(filepath.Join(t.TempDir(), "dialogs.json"))
+	options := MessageOptions{
+		Type:          MessageTypeQuestion,
+		Buttons:       []string{"Delete", "Keep"},
+		CancelButton:  "Keep",
+		DontAskAgain:  "confirm-delete",
+		DefaultButton: "Delete",
+	}
+
+	shown := 0
+	show := func(label string, dontAskAgain bool) func(MessageOptions) (string, bool, error) {
+		return func(options MessageOptions) (string, bool, error) {
+			shown++
+			return label, dontAskAgain, nil
+		}
+	}
+
+	// Dismissing is never remembered, even with the checkbox ticked
+	result, _ := state.showMessage(options, show("", true))
+	if want := (MessageResult{Button: "Keep", Cancelled: true}); result != want {
+		t.Errorf("dismissed result = %+v, want %+v", result, want)
+	}
+
+	result, _ = state.showMessage(options, show("Delete", true))
+	if want := (MessageResult{Button: "Delete", DontAskAgain: true}); result != want {
+		t.Errorf("first result = %+v, want %+v", result, want)
+	}
+
+	// A fresh instance skips the dialog with the persisted answer
+	state = newDialogState(state.path)
+	result, _ = state.showMessage(options, show("Keep", false))
+	if want := (MessageResult{Button: "Delete", DontAskAgain: true, Remembered: true}); result != want {
+		t.Errorf("remembered result = %+v, want %+v", result, want)
+	}
+	if shown != 2 {
+		t.Errorf("dialog shown %d times, want 2", shown)
+	}
+
+	if err := state.forgetChoices("confirm-delete"); err != nil {
+		t.Fatal(err)
+	}
+	result, _ = state.showMessage(options, show("Keep", false))
+	if want := (MessageResult{Button: "Keep", Cancelled: true}); result != want || shown != 3 {
+		t.Errorf("after reset result = %+v shown %d, want %+v shown 3", result, shown, want)
+	}
+}
+
+func TestShowMessageWithoutCheckbox(t *testing.T) {
+	state := newDialogState(filepath.Join(t.TempDir(), "dialogs.json"))
+	options := MessageOptions{Buttons: []string{"Replace", "Cancel"}, DontAskAgain: "replace"}
+
+	// Without a checkbox the dialog is shown every time and never remembered
+	shown := 0
+	show := func(MessageOptions) (string, bool, error) {
+		shown++
+		return "Replace", false, nil
+	}
+	for i := 0; i < 2; i++ {
+		result, err := state.showMessage(options, show)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (MessageResult{Button: "Replace"}); result != want {
+			t.Errorf("result = %+v, want %+v", result, want)
+		}
+	}
+	if shown != 2 {
+		t.Errorf("dialog shown %d times, want 2", shown)
+	}
+	if button, ok := state.rememberedChoice("replace"); ok {
+		t.Errorf("remembered %q although the dialog had no checkbox", button)
+	}
+}
@@ -0,0 +1,184 @@
+//go:build windows
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	dialogComctl32               = windows.NewLazySystemDLL("comctl32.dll")
+	dialogUser32                 = windows.NewLazySystemDLL("user32.dll")
+	procTaskDialogIndirect       = dialogComctl32.NewProc("TaskDialogIndirect")
+	procCreateIconFromResourceEx = dialogUser32.NewProc("CreateIconFromResourceEx")
+	procDestroyIcon              = dialogUser32.NewProc("DestroyIcon")
+)
+
+const (
+	tdfUseHiconMain             = 0x0002
+	tdfAllowDialogCancellation  = 0x0008
+	tdfPositionRelativeToWindow = 0x1000
+	tdfSizeToContent            = 0x01000000
+
+	// Stock icons, MAKEINTRESOURCE(-1) to MAKEINTRESOURCE(-3)
+	tdWarningIcon     = 0xFFFF
+	tdErrorIcon       = 0xFFFE
+	tdInformationIcon = 0xFFFD
+
+	// taskDialogFirstButton keeps custom button IDs clear of IDOK, IDCANCEL and friends
+	taskDialogFirstButton = 100
+	idCancel              = 2
+
+	lrDefaultSize = 0x0040
+)
+
+// taskDialogConfig builds a TASKDIALOGCONFIG, which is declared with 1-byte
+// packing and so cannot be expressed as a Go struct
+type taskDialogConfig struct {
+	buf []byte
+}
+
+func (c *taskDialogConfig) uint32(v uint32) {
+	c.buf = binary.LittleEndian.AppendUint32(c.buf, v)
+}
+
+func (c *taskDialogConfig) pointer(v uintptr) {
+	if unsafe.Sizeof(v) == 8 {
+		c.buf = binary.LittleEndian.AppendUint64(c.buf, uint64(v))
+	} else {
+		c.buf = binary.LittleEndian.AppendUint32(c.buf, uint32(v))
+	}
+}
+
+// nativeMessageDialog shows options as a task dialog, which unlike a message
+// box supports custom buttons and a real "don't ask again" checkbox. It
+// reports false if task dialogs are unavailable, i.e. when the executable's
+// manifest does not enable Common Controls 6.
+func nativeMessageDialog(options MessageOptions) (string, bool, bool, error) {
+	if err := procTaskDialogIndirect.Find(); err != nil {
+		return "", false, false, nil
+	}
+
+	// Strings and buttons must stay reachable until the dialog closes
+	var keep [][]uint16
+	text := func(s string) uintptr {
+		if s == "" {
+			return 0
+		}
+		encoded, _ := windows.UTF16FromString(strings.ReplaceAll(s, "\x00", ""))
+		keep = append(keep, encoded)
+		return uintptr(unsafe.Pointer(&encoded[0]))
+	}
+
+	// TASKDIALOG_BUTTON is also packed: an int ID followed by a string pointer
+	var buttons taskDialogConfig
+	defaultID := uint32(0)
+	for i, label := range options.Buttons {
+		id := uint32(taskDialogFirstButton + i)
+		if label == options.DefaultButton {
+			defaultID = id
+		}
+		buttons.uint32(id)
+		buttons.pointer(text(label))
+	}
+
+	flags := uint32(tdfAllowDialogCancellation | tdfPositionRelativeToWindow | tdfSizeToContent)
+	var mainIcon uintptr
+	switch options.Type {
+	case MessageTypeWarning:
+		mainIcon = tdWarningIcon
+	case MessageTypeError:
+		mainIcon = tdErrorIcon
+	case MessageTypeInfo:
+		mainIcon = tdInformationIcon
+	}
+	if len(options.icon) > 0 {
+		// PNG data is accepted as an icon resource since Windows Vista
+		icon, _, _ := procCreateIconFromResourceEx.Call(
+			uintptr(unsafe.Pointer(&options.icon[0])), uintptr(len(options.icon)),
+			1, 0x00030000, 0, 0, lrDefaultSize)
+		if icon != 0 {
+			defer procDestroyIcon.Call(icon)
+			mainIcon = icon
+			flags |= tdfUseHiconMain
+		}
+	}
+
+	var verification uintptr
+	if options.DontAskAgain != "" {
+		verification = text(options.DontAskAgainLabel)
+	}
+
+	var config taskDialogConfig
+	config.uint32(0) // cbSize, set below
+	config.pointer(uintptr(messageDialogParent()))
+	config.pointer(0) // hInstance
+	config.uint32(flags)
+	config.uint32(0) // dwCommonButtons
+	config.pointer(text(options.Title))
+	config.pointer(mainIcon)
+	config.pointer(0) // pszMainInstruction
+	config.pointer(text(options.Message))
+	config.uint32(uint32(len(options.Buttons)))
+	config.pointer(uintptr(unsafe.Pointer(&buttons.buf[0])))
+	config.uint32(defaultID)
+	config.uint32(0)  // cRadioButtons
+	config.pointer(0) // pRadioButtons
+	config.uint32(0)  // nDefaultRadioButton
+	config.pointer(verification)
+	config.pointer(0) // pszExpandedInformation
+	config.pointer(0) // pszExpandedControlText
+	config.pointer(0) // pszCollapsedControlText
+	config.pointer(0) // hFooterIcon
+	config.pointer(0) // pszFooter
+	config.pointer(0) // pfCallback
+	config.pointer(0) // lpCallbackData
+	config.uint32(0)  // cxWidth
+	binary.LittleEndian.PutUint32(config.buf, uint32(len(config.buf)))
+
+	// The dialog runs a modal message loop on the calling thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var pressed int32
+	var checked int32
+	hr, _, _ := procTaskDialogIndirect.Call(
+		uintptr(unsafe.Pointer(&config.buf[0])),
+		uintptr(unsafe.Pointer(&pressed)),
+		0,
+		uintptr(unsafe.Pointer(&checked)))
+	runtime.KeepAlive(keep)
+	runtime.KeepAlive(buttons.buf)
+	if hr != 0 {
+		return "", false, true, fmt.Errorf("task dialog failed: HRESULT 0x%08X", uint32(hr))
+	}
+
+	if pressed == idCancel {
+		return "", false, true, nil
+	}
+	index := int(pressed) - taskDialogFirstButton
+	if index < 0 || index >= len(options.Buttons) {
+		return "", false, true, nil
+	}
+	return options.Buttons[index], checked != 0, true, nil
+}
+
+// messageDialogParent returns the app's foreground window so the dialog is
+// modal to it, or 0 if another application is in front
+func messageDialogParent() windows.HWND {
+	hwnd := windows.GetForegroundWindow()
+	if hwnd == 0 {
+		return 0
+	}
+	var pid uint32
+	if _, err := windows.GetWindowThreadProcessId(hwnd, &pid); err != nil || pid != windows.GetCurrentProcessId() {
+		return 0
+	}
+	return hwnd
+}
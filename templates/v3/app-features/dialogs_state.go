@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// maxRecentFiles bounds the recent-files list
+const maxRecentFiles = 10
+
+// ErrInvalidDialogPurpose is returned for purpose keys that cannot be stored
+var ErrInvalidDialogPurpose = errors.New("dialog purpose must be 1-64 letters, digits, '.', '-' or '_'")
+
+// dialogPurposePattern limits purpose keys to short identifiers like "import"
+var dialogPurposePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// dialogStateFile is the persisted form of dialogState
+type dialogStateFile struct {
+	// Directories maps a purpose key to the last directory chosen for it
+	Directories map[string]string `json:"directories,omitempty"`
+	// RecentFiles lists files chosen in open dialogs, newest first
+	RecentFiles []string `json:"recentFiles,omitempty"`
+	// Choices maps a message's DontAskAgain key to the remembered button
+	Choices map[string]string `json:"choices,omitempty"`
+}
+
+// dialogState remembers where each kind of dialog was last used, which files
+// were opened recently and which messages should not be asked again
+type dialogState struct {
+	mu   sync.Mutex
+	path string
+	data dialogStateFile
+}
+
+var (
+	dialogStateStore *dialogState
+	dialogStateOnce  sync.Once
+)
+
+// sharedDialogState returns the shared dialog state, loading it on first use
+func sharedDialogState() *dialogState {
+	dialogStateOnce.Do(func() {
+		path, err := dialogStatePath()
+		if err != nil {
+			path = ""
+		}
+		dialogStateStore = newDialogState(path)
+	})
+	return dialogStateStore
+}
+
+// dialogStatePath returns where the dialog state is persisted, next to the app config
+func dialogStatePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".{{PROJECT_NAME}}", "dialogs.json"), nil
+}
+
+// newDialogState creates dialog state persisted at path ("" keeps it in memory)
+func newDialogState(path string) *dialogState {
+	s := &dialogState{path: path}
+	s.load()
+	return s
+}
+
+// load reads the persisted state, ignoring a missing or corrupt file
+func (s *dialogState) load() {
+	if s.path == "" {
+		return
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	var state dialogStateFile
+	if err := json.Unmarshal(data, &state); err != nil {
+		return
+	}
+	s.data = state
+}
+
+// save persists the state; the file is private since it reveals recent paths
+func (s *dialogState) save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// prepare validates options and, when a purpose is given, starts the dialog
+// where that purpose was last used; DefaultDirectory only applies the first
+// time. A remembered directory that no longer exists is forgotten.
+func (s *dialogState) prepare(options FileDialogOptions) (FileDialogOptions, error) {
+	if options.Purpose != "" && !dialogPurposePattern.MatchString(options.Purpose) {
+		return options, ErrInvalidDialogPurpose
+	}
+
+	if options.Purpose != "" {
+		s.mu.Lock()
+		dir, ok := s.data.Directories[options.Purpose]
+		if ok {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				options.DefaultDirectory = dir
+			} else {
+				delete(s.data.Directories, options.Purpose)
+				s.logError(s.save())
+			}
+		}
+		s.mu.Unlock()
+	}
+	return options.normalize()
+}
+
+// remember records a dialog's selection: the directory for its purpose and,
+// for open dialogs, the files in the recent list. An empty selection means the
+// dialog was cancelled and changes nothing.
+func (s *dialogState) remember(purpose string, paths []string, directory, recent bool) {
+	if len(paths) == 0 || paths[0] == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if purpose != "" {
+		dir := paths[0]
+		if !directory {
+			dir = filepath.Dir(dir)
+		}
+		if s.data.Directories == nil {
+			s.data.Directories = make(map[string]string)
+		}
+		s.data.Directories[purpose] = dir
+	}
+	if recent {
+		s.addRecent(paths)
+	}
+	s.logError(s.save())
+}
+
+// addRecent moves paths to the front of the recent list; s.mu must be held
+func (s *dialogState) addRecent(paths []string) {
+	recent := make([]string, 0, maxRecentFiles)
+	seen := make(map[string]bool)
+	for _, path := range append(append([]string{}, paths...), s.data.RecentFiles...) {
+		if path == "" || seen[path] || len(recent) == maxRecentFiles {
+			continue
+		}
+		seen[path] = true
+		recent = append(recent, path)
+	}
+	s.data.RecentFiles = recent
+}
+
+// recentFiles returns the recent files that still exist, newest first
+func (s *dialogState) recentFiles() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files := make([]string, 0, len(s.data.RecentFiles))
+	for _, path := range s.data.RecentFiles {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// clearRecentFiles empties the recent-files list
+func (s *dialogState) clearRecentFiles() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.RecentFiles = nil
+	return s.save()
+}
+
+// rememberedChoice returns the button remembered for a DontAskAgain key
+func (s *dialogState) rememberedChoice(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	button, ok := s.data.Choices[key]
+	return button, ok
+}
+
+// rememberChoice persists the button chosen with "don't ask again"
+func (s *dialogState) rememberChoice(key, button string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data.Choices == nil {
+		s.data.Choices = make(map[string]string)
+	}
+	s.data.Choices[key] = button
+	s.logError(s.save())
+}
+
+// forgetChoices asks again for a DontAskAgain key, or for every key if it is empty
+func (s *dialogState) forgetChoices(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key == "" {
+		s.data.Choices = nil
+	} else {
+		delete(s.data.Choices, key)
+	}
+	return s.save()
+}
+
+// logError reports a failure to persist dialog state; the selection itself is
+// still returned to the frontend
+func (s *dialogState) logError(err error) {
+	if err != nil {
+		fmt.Println("dialogs:", err)
+	}
+}
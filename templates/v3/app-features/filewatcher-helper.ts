@@ -1,43 +1,130 @@
 // File Watcher Helper
-import { WatchFile, UnwatchFile, StopWatching, OnFileChange } from '../wailsjs/go/main/App'
+import { FileWatcherService } from '../bindings/changeme'
+import { Events } from '@wailsio/runtime'
 
-export async function watchFile(path: string) {
+export interface FileEvent {
+  watchId: string
+  path: string
+  // "create", "write", "remove", "rename", "chmod", or "overflow" when events were dropped
+  op: string
+}
+
+// Globs use doublestar syntax and are relative to the watched directory
+export interface WatchOptions {
+  recursive: boolean
+  include?: string[]
+  exclude?: string[]
+  // Skip paths matched by .gitignore/.ignore files, and .git itself
+  respectIgnore: boolean
+  // 0 means unlimited
+  maxDepth?: number
+  maxFiles?: number
+  // Scan instead of using OS notifications; automatic on NFS, SMB and FUSE mounts
+  poll?: boolean
+  // Seconds between scans; 0 uses the default of 2
+  pollInterval?: number
+  // Compare file contents when polling, catching writes that keep size and mtime
+  hashContent?: boolean
+}
+
+export interface WatchInfo {
+  id: string
+  path: string
+  recursive: boolean
+  options: WatchOptions
+  backend: 'notify' | 'poll'
+  // The network or FUSE filesystem that made the watch poll
+  filesystem?: string
+}
+
+// Watch a file, or a directory and everything below it except .git, node_modules
+// and ignored paths; returns the watch ID
+export async function watchFile(path: string): Promise<string | undefined> {
   try {
-    await WatchFile(path)
-    console.log('Started watching:', path)
+    const id = await FileWatcherService.WatchFile(path)
+    console.log('Started watching:', path, id)
+    return id
   } catch (error) {
     console.error('Failed to watch file:', error)
   }
 }
 
-export async function unwatchFile(path: string) {
+// Watch with filters; fails with an explanation if the file or inotify watch limit is exceeded
+export async function watchWithOptions(path: string, options: WatchOptions): Promise<string | undefined> {
+  try {
+    return await FileWatcherService.WatchFileWithOptions(path, options)
+  } catch (error) {
+    console.error('Failed to watch:', error)
+  }
+}
+
+// Stop a watch by its ID, or every watch on a path
+export async function unwatchFile(idOrPath: string) {
   try {
-    await UnwatchFile(path)
-    console.log('Stopped watching:', path)
+    await FileWatcherService.UnwatchFile(idOrPath)
+    console.log('Stopped watching:', idOrPath)
   } catch (error) {
     console.error('Failed to unwatch file:', error)
   }
 }
 
+// Watches persist across restarts and are restored at startup. Changes made while the
+// app was closed are held until onFileEvent subscribes.
+export async function listWatches(): Promise<WatchInfo[]> {
+  return await FileWatcherService.ListWatches()
+}
+
 export async function stopAllWatching() {
   try {
-    await StopWatching()
+    await FileWatcherService.StopWatching()
     console.log('Stopped all file watching')
   } catch (error) {
     console.error('Failed to stop watching:', error)
   }
 }
 
+// Subscribe to coalesced file events, including those delivered together in an
+// fs:batch message when a whole tree changes; returns a function that unsubscribes.
+// The first subscriber also receives the changes held since startup.
+export function onFileEvent(listener: (event: FileEvent) => void) {
+  const offEvent = Events.On('fs:event', (event) => listener(event.data as FileEvent))
+  const offBatch = Events.On('fs:batch', (event) => (event.data as FileEvent[]).forEach(listener))
+  // Tell the backend we are listening; it returns what happened before now
+  FileWatcherService.FileWatcherReady().then((held) => held.forEach(listener))
+  return () => {
+    offEvent()
+    offBatch()
+  }
+}
+
 // Example usage
 export async function setupFileWatcher() {
-  // Watch a specific file
-  await watchFile('/path/to/config.json')
-  
-  // Watch a directory
-  await watchFile('/path/to/directory')
-  
-  // Handle file changes (in production, use Wails events)
-  // wails.Events.On('file:changed', (path) => {
-  //   console.log('File changed:', path)
-  // })
+  let configWatch: string | undefined
+
+  const unsubscribe = onFileEvent((event) => {
+    if (event.op === 'overflow') {
+      console.warn('Events were dropped, rescan:', event.path)
+      return
+    }
+    if (event.watchId === configWatch) {
+      console.log('Config changed:', event.op)
+      return
+    }
+    console.log(`${event.op}:`, event.path)
+  })
+
+  // Last session's watches were restored at startup; their offline changes arrive through the listener above
+  console.log('Restored watches:', await listWatches())
+
+  // Watching the same path with the same options again returns the existing ID
+  configWatch = await watchFile('/path/to/config.json')
+  await watchWithOptions('/path/to/notes', {
+    recursive: true,
+    include: ['**/*.md'],
+    exclude: ['**/drafts/**'],
+    respectIgnore: true,
+    maxFiles: 10000,
+  })
+
+  return unsubscribe
 }
@@ -1,65 +1,130 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"path/filepath"
-	"time"
+	"sync"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
 )
 
-// FileWatcher represents a file system watcher
-type FileWatcher struct {
-	paths    []string
-	interval time.Duration
-	callback func(string, string) // path, event type
-	stopChan chan bool
-	running  bool
-}
+var (
+	fileWatcherMu     sync.Mutex
+	activeFileWatcher *FileWatcher
+	// fileWatcherReady is set once the frontend has called FileWatcherReady
+	fileWatcherReady bool
+)
+
+// FileWatcherService exposes recursive file watching to the frontend
+type FileWatcherService struct{}
 
-// NewFileWatcher creates a new file watcher
-func (a *App) NewFileWatcher() *FileWatcher {
-	return &FileWatcher{
-		paths:    make([]string, 0),
-		interval: 1 * time.Second,
-		stopChan: make(chan bool),
-		running:  false,
+// ServiceStartup restores the persisted watches. It runs in the background
+// because the first scan of a network share can be slow.
+func (f *FileWatcherService) ServiceStartup(ctx context.Context, options application.ServiceOptions) error {
+	if !fileWatcherOptions.Persist {
+		return nil
 	}
+	go func() {
+		if _, err := f.watcher(); err != nil {
+			fmt.Println("file watcher:", err)
+		}
+	}()
+	return nil
+}
+
+// ServiceShutdown stops the watcher, saving snapshots of persisted watches
+func (f *FileWatcherService) ServiceShutdown() error {
+	return f.StopWatching()
 }
 
-// WatchFile adds a file or directory to watch
-func (a *App) WatchFile(path string) error {
-	absPath, err := filepath.Abs(path)
+// watcher returns the running file watcher, starting one and restoring the
+// persisted watches on first use. Until the frontend calls FileWatcherReady,
+// events are held so changes made while the app was closed are not lost.
+func (f *FileWatcherService) watcher() (*FileWatcher, error) {
+	fileWatcherMu.Lock()
+	defer fileWatcherMu.Unlock()
+
+	if activeFileWatcher != nil {
+		return activeFileWatcher, nil
+	}
+	w, err := NewFileWatcher(context.Background(), func(name string, data interface{}) {
+		application.Get().Event.Emit(name, data)
+	})
 	if err != nil {
-		return fmt.Errorf("invalid path: %w", err)
+		return nil, err
+	}
+	if fileWatcherOptions.Persist {
+		if !fileWatcherReady {
+			w.Hold()
+		}
+		if dir, err := watchStoreDir(); err == nil {
+			w.restore(newWatchStore(dir))
+		}
 	}
+	activeFileWatcher = w
+	return w, nil
+}
 
-	fmt.Printf("Watching: %s\n", absPath)
-	
-	// In production, use github.com/fsnotify/fsnotify for real-time events
-	// This is a simplified polling-based implementation
-	
-	return nil
+// FileWatcherReady is called by the frontend once it listens for fs:event and
+// fs:batch messages. It returns the changes held since startup, including
+// those made while the app was closed.
+func (f *FileWatcherService) FileWatcherReady() []FileEvent {
+	fileWatcherMu.Lock()
+	fileWatcherReady = true
+	w := activeFileWatcher
+	fileWatcherMu.Unlock()
+
+	if w == nil {
+		return []FileEvent{}
+	}
+	return w.Ready()
+}
+
+// WatchFile watches a file, or a directory tree recursively, and returns the
+// watch ID carried by its fs:event events. Directories skip .git,
+// node_modules and anything matched by .gitignore or .ignore files.
+func (f *FileWatcherService) WatchFile(path string) (string, error) {
+	return f.WatchFileWithOptions(path, defaultWatchOptions)
 }
 
-// UnwatchFile removes a file or directory from watching
-func (a *App) UnwatchFile(path string) error {
-	absPath, err := filepath.Abs(path)
+// WatchFileWithOptions watches a path with include/exclude globs, ignore-file
+// handling, a depth limit and a file-count limit
+func (f *FileWatcherService) WatchFileWithOptions(path string, options WatchOptions) (string, error) {
+	w, err := f.watcher()
 	if err != nil {
-		return fmt.Errorf("invalid path: %w", err)
+		return "", err
 	}
+	return w.Watch(path, options)
+}
 
-	fmt.Printf("Stopped watching: %s\n", absPath)
-	return nil
+// UnwatchFile removes a watch by ID, or every watch on a path
+func (f *FileWatcherService) UnwatchFile(idOrPath string) error {
+	w, err := f.watcher()
+	if err != nil {
+		return err
+	}
+	return w.Unwatch(idOrPath)
 }
 
-// StopWatching stops all file watching
-func (a *App) StopWatching() error {
-	fmt.Println("Stopped all file watching")
-	return nil
+// ListWatches returns the active watches
+func (f *FileWatcherService) ListWatches() ([]WatchInfo, error) {
+	w, err := f.watcher()
+	if err != nil {
+		return nil, err
+	}
+	return w.List(), nil
 }
 
-// OnFileChange registers a callback for file changes
-// In production, emit Wails events instead of using callbacks
-func (a *App) OnFileChange(path string) (string, error) {
-	// This would be called when a file changes
-	return fmt.Sprintf("File changed: %s", path), nil
+// StopWatching stops all file watching until the next watcher call; persisted
+// watches are kept and restored then. Use UnwatchFile to forget a watch.
+func (f *FileWatcherService) StopWatching() error {
+	fileWatcherMu.Lock()
+	w := activeFileWatcher
+	activeFileWatcher = nil
+	fileWatcherMu.Unlock()
+
+	if w == nil {
+		return nil
+	}
+	return w.Close()
 }
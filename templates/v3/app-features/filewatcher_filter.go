@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ErrWatchTooLarge is returned when a watch would cover more entries than MaxFiles
+var ErrWatchTooLarge = errors.New("watch exceeds the file limit")
+
+// ignoreFileNames are read in every watched directory when RespectIgnore is set
+var ignoreFileNames = []string{".gitignore", ".ignore"}
+
+// WatchOptions selects what a watch covers. Globs use doublestar syntax
+// ("**/*.go", "dist/**") and are matched against slash-separated paths
+// relative to the watched directory.
+type WatchOptions struct {
+	// Recursive watches subdirectories, including ones created later
+	Recursive bool `json:"recursive"`
+	// Include limits file events to matching paths; empty includes everything
+	Include []string `json:"include,omitempty"`
+	// Exclude skips matching files and directories, and everything below them
+	Exclude []string `json:"exclude,omitempty"`
+	// RespectIgnore skips paths ignored by .gitignore or .ignore files, and .git itself
+	RespectIgnore bool `json:"respectIgnore"`
+	// MaxDepth limits how many directory levels below the root are watched; 0 is unlimited
+	MaxDepth int `json:"maxDepth,omitempty"`
+	// MaxFiles fails the watch if it would cover more entries; 0 is unlimited
+	MaxFiles int `json:"maxFiles,omitempty"`
+	// Poll scans for changes instead of using OS notifications. Polling is also
+	// chosen automatically on NFS, SMB and FUSE mounts.
+	Poll bool `json:"poll,omitempty"`
+	// PollInterval is the scan period in seconds; 0 uses FileWatcherOptions.PollInterval
+	PollInterval int `json:"pollInterval,omitempty"`
+	// HashContent makes polling compare file contents, catching writes that keep
+	// the size and modification time
+	HashContent bool `json:"hashContent,omitempty"`
+}
+
+// defaultWatchOptions is used by WatchFile
+var defaultWatchOptions = WatchOptions{
+	Recursive:     true,
+	Exclude:       []string{"**/.git", "**/node_modules"},
+	RespectIgnore: true,
+}
+
+// validate checks that every glob is well formed
+func (o WatchOptions) validate() error {
+	for _, pattern := range append(append([]string{}, o.Include...), o.Exclude...) {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid glob pattern %q", pattern)
+		}
+	}
+	if o.MaxDepth < 0 || o.MaxFiles < 0 || o.PollInterval < 0 {
+		return errors.New("maxDepth, maxFiles and pollInterval must not be negative")
+	}
+	return nil
+}
+
+// ignoreRule is one line of a .gitignore or .ignore file
+type ignoreRule struct {
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// parseIgnoreRule converts gitignore syntax to a doublestar pattern relative
+// to the ignore file's directory
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	// Patterns without an inner slash match at any depth
+	if strings.Contains(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+	} else {
+		line = "**/" + line
+	}
+	if line == "" || !doublestar.ValidatePattern(line) {
+		return ignoreRule{}, false
+	}
+	rule.pattern = line
+	return rule, true
+}
+
+// readIgnoreRules loads the ignore files in dir
+func readIgnoreRules(dir string) []ignoreRule {
+	var rules []ignoreRule
+	for _, name := range ignoreFileNames {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+				rules = append(rules, rule)
+			}
+		}
+		file.Close()
+	}
+	return rules
+}
+
+// isIgnoreFile reports whether path is a .gitignore or .ignore file
+func isIgnoreFile(path string) bool {
+	base := filepath.Base(path)
+	for _, name := range ignoreFileNames {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// loadIgnores reads dir's ignore files into the watch
+func (watch *fileWatch) loadIgnores(dir string) {
+	if !watch.options.RespectIgnore {
+		return
+	}
+	if rules := readIgnoreRules(dir); len(rules) > 0 {
+		watch.ignores[dir] = rules
+	} else {
+		delete(watch.ignores, dir)
+	}
+}
+
+// relPath returns path relative to the watch root with forward slashes
+func (watch *fileWatch) relPath(path string) string {
+	rel, err := filepath.Rel(watch.root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// depth is the number of directory levels between the root and path
+func (watch *fileWatch) depth(path string) int {
+	if path == watch.root {
+		return 0
+	}
+	return strings.Count(watch.relPath(path), "/") + 1
+}
+
+// watchesDir reports whether a directory at depth should get its own watch
+func (watch *fileWatch) watchesDir(depth int) bool {
+	if depth == 0 {
+		return true
+	}
+	if !watch.recursive {
+		return false
+	}
+	return watch.options.MaxDepth == 0 || depth <= watch.options.MaxDepth
+}
+
+// walkTree visits start and the entries below it that pass the watch's filters
+// and depth limit. found is called for every entry below start, and enter for
+// every directory that is descended into, after its ignore files are loaded.
+func (watch *fileWatch) walkTree(start string, found func(path string, entry fs.DirEntry) error, enter func(dir string) error) error {
+	return filepath.WalkDir(start, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == start {
+				return err
+			}
+			// Skip unreadable subdirectories rather than failing the whole watch
+			return nil
+		}
+		if path != start {
+			if !watch.allows(path, entry.IsDir()) {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if err := found(path, entry); err != nil {
+				return err
+			}
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if !watch.watchesDir(watch.depth(path)) {
+			return filepath.SkipDir
+		}
+		watch.loadIgnores(path)
+		return enter(path)
+	})
+}
+
+// limitEntries returns a walkTree callback that fails once the walk has found
+// more than MaxFiles entries
+func (watch *fileWatch) limitEntries() func(string, fs.DirEntry) error {
+	entries := 0
+	return func(string, fs.DirEntry) error {
+		entries++
+		if limit := watch.options.MaxFiles; limit > 0 && entries > limit {
+			return fmt.Errorf("%w: %s has more than %d entries; narrow it with include or exclude globs", ErrWatchTooLarge, watch.root, limit)
+		}
+		return nil
+	}
+}
+
+// allows reports whether an entry of a directory watch passes its filters
+func (watch *fileWatch) allows(path string, isDir bool) bool {
+	if !watch.isDir || path == watch.root {
+		return true
+	}
+
+	// An excluded or ignored directory hides everything below it
+	rel := watch.relPath(path)
+	for i, c := range rel {
+		if c == '/' && watch.skips(rel[:i], true) {
+			return false
+		}
+	}
+	if watch.skips(rel, isDir) {
+		return false
+	}
+
+	if isDir || len(watch.options.Include) == 0 {
+		return true
+	}
+	for _, pattern := range watch.options.Include {
+		if ok, _ := doublestar.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// skips reports whether the entry at rel itself is excluded or ignored
+func (watch *fileWatch) skips(rel string, isDir bool) bool {
+	for _, pattern := range watch.options.Exclude {
+		if ok, _ := doublestar.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	if !watch.options.RespectIgnore {
+		return false
+	}
+	if rel == ".git" || strings.HasSuffix(rel, "/.git") {
+		return true
+	}
+
+	// Rules from deeper ignore files override shallower ones, and later lines
+	// override earlier ones, as in git
+	ignored := false
+	dir := watch.root
+	segments := strings.Split(rel, "/")
+	for i := range segments {
+		for _, rule := range watch.ignores[dir] {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if ok, _ := doublestar.Match(rule.pattern, strings.Join(segments[i:], "/")); ok {
+				ignored = !rule.negate
+			}
+		}
+		dir = filepath.Join(dir, segments[i])
+	}
+	return ignored
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIgnoreRule(t *testing.T) {
+	tests := []struct {
+		line string
+		want ignoreRule
+		ok   bool
+	}{
+		{"# comment", ignoreRule{}, false},
+		{"   ", ignoreRule{}, false},
+		{"*.log", ignoreRule{pattern: "**/*.log"}, true},
+		{"build/", ignoreRule{pattern: "**/build", dirOnly: true}, true},
+		{"/dist", ignoreRule{pattern: "dist"}, true},
+		{"docs/*.md", ignoreRule{pattern: "docs/*.md"}, true},
+		{"!keep.log", ignoreRule{pattern: "**/keep.log", negate: true}, true},
+		{`\#file`, ignoreRule{pattern: "**/#file"}, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseIgnoreRule(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseIgnoreRule(%q) = %+v, %v, want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestWatchAllows(t *testing.T) {
+	root := t.TempDir()
+	watch := &fileWatch{
+		root:  root,
+		isDir: true,
+		options: WatchOptions{
+			Recursive:     true,
+			Include:       []string{"**/*.go", "**/*.log"},
+			Exclude:       []string{"**/node_modules"},
+			RespectIgnore: true,
+		},
+		ignores: map[string][]ignoreRule{},
+	}
+	for _, line := range []string{"*.log", "!keep.log", "build/"} {
+		rule, _ := parseIgnoreRule(line)
+		watch.ignores[root] = append(watch.ignores[root], rule)
+	}
+	// A nested ignore file re-includes what the root one ignores
+	nested, _ := parseIgnoreRule("!debug.log")
+	watch.ignores[filepath.Join(root, "pkg")] = []ignoreRule{nested}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"main.go", false, true},
+		{"README.md", false, false},
+		{"pkg", true, true},
+		{"pkg/util.go", false, true},
+		{"node_modules", true, false},
+		{"web/node_modules/lib/index.go", false, false},
+		{".git", true, false},
+		{".git/config.go", false, false},
+		{"app.log", false, false},
+		{"keep.log", false, true},
+		{"pkg/debug.log", false, true},
+		{"build", true, false},
+		{"build/out.go", false, false},
+		{"cmd/build", true, false},
+		{"cmd/build/main.go", false, false},
+	}
+
+	for _, tt := range tests {
+		got := watch.allows(filepath.Join(root, filepath.FromSlash(tt.path)), tt.isDir)
+		if got != tt.want {
+			t.Errorf("allows(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWatchOptionsValidate(t *testing.T) {
+	if err := (WatchOptions{Include: []string{"src/[a-"}}).validate(); err == nil {
+		t.Error("validate accepted a malformed glob")
+	}
+	if err := (WatchOptions{MaxDepth: -1}).validate(); err == nil {
+		t.Error("validate accepted a negative depth")
+	}
+	if err := defaultWatchOptions.validate(); err != nil {
+		t.Errorf("default options invalid: %v", err)
+	}
+}
+
+// makeTree creates files (and their directories) below root
+func makeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// watchedDirs returns the directories a watch holds, relative to root
+func watchedDirs(w *FileWatcher, id, root string) map[string]bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	dirs := make(map[string]bool)
+	for dir := range w.watches[id].dirs {
+		rel, _ := filepath.Rel(root, dir)
+		dirs[filepath.ToSlash(rel)] = true
+	}
+	return dirs
+}
+
+func TestFileWatcherSkipsIgnoredDirectories(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{
+		".gitignore":              "build/\n",
+		"src/main.go":             "package main",
+		"build/app":               "binary",
+		"node_modules/pkg/i.js":   "",
+		".git/HEAD":               "ref: refs/heads/main",
+		"src/generated/.ignore":   "*.pb.go\n",
+		"src/generated/api.pb.go": "",
+	})
+	w, events := newTestFileWatcher(t)
+
+	id, err := w.Watch(root, defaultWatchOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirs := watchedDirs(w, id, root)
+	for _, dir := range []string{".", "src", "src/generated"} {
+		if !dirs[dir] {
+			t.Errorf("%s not watched", dir)
+		}
+	}
+	for _, dir := range []string{"build", "node_modules", "node_modules/pkg", ".git"} {
+		if dirs[dir] {
+			t.Errorf("%s watched", dir)
+		}
+	}
+
+	os.WriteFile(filepath.Join(root, "src", "generated", "more.pb.go"), nil, 0644)
+	expectNoFileEvent(t, events)
+
+	file := filepath.Join(root, "src", "generated", "handwritten.go")
+	os.WriteFile(file, nil, 0644)
+	waitForFileEvent(t, events, file)
+}
+
+func TestFileWatcherMaxDepth(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{"a/b/c/deep.txt": ""})
+	w, _ := newTestFileWatcher(t)
+
+	id, err := w.Watch(root, WatchOptions{Recursive: true, MaxDepth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirs := watchedDirs(w, id, root)
+	if len(dirs) != 2 || !dirs["."] || !dirs["a"] {
+		t.Errorf("watched dirs = %v, want root and a", dirs)
+	}
+}
+
+func TestFileWatcherMaxFiles(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{"1": "", "2": "", "3": "", "sub/4": ""})
+	w, _ := newTestFileWatcher(t)
+
+	_, err := w.Watch(root, WatchOptions{Recursive: true, MaxFiles: 3})
+	if !errors.Is(err, ErrWatchTooLarge) {
+		t.Fatalf("Watch error = %v, want ErrWatchTooLarge", err)
+	}
+	if len(w.dirRefs) != 0 || len(w.List()) != 0 {
+		t.Errorf("failed watch left dirs %v", w.dirRefs)
+	}
+
+	// Excluded entries do not count towards the limit
+	if _, err := w.Watch(root, WatchOptions{Recursive: true, MaxFiles: 3, Exclude: []string{"sub"}}); err != nil {
+		t.Errorf("Watch with exclude error = %v", err)
+	}
+}
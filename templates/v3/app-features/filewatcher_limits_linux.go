@@ -0,0 +1,35 @@
+//go:build linux
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// explainWatchError turns inotify exhaustion into an actionable error
+func explainWatchError(err error) error {
+	switch {
+	case errors.Is(err, syscall.ENOSPC):
+		return fmt.Errorf("%w: all %s inotify watches (fs.inotify.max_user_watches) are in use; "+
+			"exclude large directories or raise the limit with `sudo sysctl fs.inotify.max_user_watches=524288`",
+			ErrWatchLimit, inotifyLimit("max_user_watches"))
+	case errors.Is(err, syscall.EMFILE):
+		return fmt.Errorf("%w: all %s inotify instances (fs.inotify.max_user_instances) are in use; "+
+			"raise the limit with `sudo sysctl fs.inotify.max_user_instances=512`",
+			ErrWatchLimit, inotifyLimit("max_user_instances"))
+	}
+	return err
+}
+
+// inotifyLimit reads a limit from /proc/sys/fs/inotify
+func inotifyLimit(name string) string {
+	data, err := os.ReadFile("/proc/sys/fs/inotify/" + name)
+	if err != nil {
+		return "available"
+	}
+	return strings.TrimSpace(string(data))
+}
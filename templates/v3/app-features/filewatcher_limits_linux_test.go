@@ -0,0 +1,23 @@
+//go:build linux
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestExplainWatchError(t *testing.T) {
+	err := explainWatchError(syscall.ENOSPC)
+	if !errors.Is(err, ErrWatchLimit) || !strings.Contains(err.Error(), "fs.inotify.max_user_watches") {
+		t.Errorf("ENOSPC explained as %v", err)
+	}
+	if err := explainWatchError(syscall.EMFILE); !strings.Contains(err.Error(), "fs.inotify.max_user_instances") {
+		t.Errorf("EMFILE explained as %v", err)
+	}
+	if err := explainWatchError(syscall.EACCES); err != syscall.EACCES {
+		t.Errorf("unrelated error changed to %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package main
+
+// explainWatchError only has extra detail for inotify on Linux
+func explainWatchError(err error) error {
+	return err
+}
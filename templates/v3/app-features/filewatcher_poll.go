@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+)
+
+// Watch backends reported in WatchInfo
+const (
+	WatchBackendNotify = "notify"
+	WatchBackendPoll   = "poll"
+)
+
+// maxPollHashSize is the largest file HashContent reads; bigger files are
+// compared by size and modification time only
+const maxPollHashSize = 64 << 20
+
+// fileState is what polling compares between scans
+type fileState struct {
+	isDir   bool
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
+	hash    [sha256.Size]byte
+	hashed  bool
+}
+
+// usePolling decides the backend for a new watch, returning the filesystem
+// type when it was detected as one without change notifications
+func usePolling(path string, options WatchOptions) (bool, string) {
+	fsType, remote := remoteFilesystem(path)
+	return options.Poll || remote, fsType
+}
+
+// pollInterval is the scan period for a polling watch
+func (w *FileWatcher) pollInterval(options WatchOptions) time.Duration {
+	if options.PollInterval > 0 {
+		return time.Duration(options.PollInterval) * time.Second
+	}
+	return w.interval
+}
+
+// startPolling scans a polling watch every interval, starting from its
+// initial snapshot, until the watch is removed or the watcher closes
+func (w *FileWatcher) startPolling(watch *fileWatch, states map[string]fileState) {
+	ctx, cancel := context.WithCancel(w.ctx)
+	watch.stop = cancel
+	w.pollers.Add(1)
+	go w.poll(ctx, watch, states)
+}
+
+// poll queues the differences between successive scans
+func (w *FileWatcher) poll(ctx context.Context, watch *fileWatch, states map[string]fileState) {
+	defer w.pollers.Done()
+
+	ticker := time.NewTicker(w.pollInterval(watch.options))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := watch.scan()
+		if err != nil {
+			// A share that is briefly unreachable must not look like mass deletion
+			w.logError(err)
+			continue
+		}
+		events := diffFileStates(watch.id, states, current)
+		states = current
+		if len(events) == 0 {
+			continue
+		}
+
+		w.mu.Lock()
+		if ctx.Err() == nil {
+			now := time.Now()
+			for _, event := range events {
+				w.debounce.add(event, false, now)
+			}
+		}
+		w.mu.Unlock()
+
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// scan records the state of every entry a watch covers. A missing root
+// yields an empty snapshot so its removal is reported. It does not touch the
+// watch's own state, so it is safe to call without holding the watcher lock.
+func (watch *fileWatch) scan() (map[string]fileState, error) {
+	states := make(map[string]fileState)
+
+	if !watch.isDir {
+		info, err := os.Stat(watch.root)
+		if errors.Is(err, fs.ErrNotExist) {
+			return states, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		states[watch.root] = watch.state(watch.root, info)
+		return states, nil
+	}
+
+	// Ignore files are loaded into a detached copy
+	walker := &fileWatch{
+		root:      watch.root,
+		isDir:     watch.isDir,
+		recursive: watch.recursive,
+		options:   watch.options,
+		ignores:   make(map[string][]ignoreRule),
+	}
+	limit := walker.limitEntries()
+	err := walker.walkTree(watch.root, func(path string, entry fs.DirEntry) error {
+		if err := limit(path, entry); err != nil {
+			return err
+		}
+		if info, err := entry.Info(); err == nil {
+			states[path] = watch.state(path, info)
+		}
+		return nil
+	}, func(string) error { return nil })
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(map[string]fileState), nil
+	}
+	return states, err
+}
+
+// state captures one entry, hashing file content when HashContent is set
+func (watch *fileWatch) state(path string, info fs.FileInfo) fileState {
+	state := fileState{isDir: info.IsDir(), mode: info.Mode()}
+	if state.isDir {
+		return state
+	}
+
+	state.size = info.Size()
+	state.modTime = info.ModTime()
+	if watch.options.HashContent && state.size <= maxPollHashSize {
+		state.hash, state.hashed = hashFile(path)
+	}
+	return state
+}
+
+// hashFile returns the SHA-256 of a file's content
+func hashFile(path string) ([sha256.Size]byte, bool) {
+	var sum [sha256.Size]byte
+	file, err := os.Open(path)
+	if err != nil {
+		return sum, false
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return sum, false
+	}
+	copy(sum[:], hash.Sum(nil))
+	return sum, true
+}
+
+// diffFileStates converts the differences between two scans into events.
+// Directory modification times are ignored since their entries are reported
+// individually. With hashes, a changed mtime over identical content is a chmod.
+func diffFileStates(watchID string, previous, current map[string]fileState) []FileEvent {
+	var events []FileEvent
+	add := func(path, op string) {
+		events = append(events, FileEvent{WatchID: watchID, Path: path, Op: op})
+	}
+
+	for path, state := range current {
+		old, ok := previous[path]
+		switch {
+		case !ok:
+			add(path, FileOpCreate)
+		case state.isDir != old.isDir:
+			add(path, FileOpRemove)
+			add(path, FileOpCreate)
+		case state.isDir:
+			if state.mode != old.mode {
+				add(path, FileOpChmod)
+			}
+		case state.size != old.size || state.hash != old.hash:
+			add(path, FileOpWrite)
+		case !state.modTime.Equal(old.modTime):
+			if state.hashed && old.hashed {
+				add(path, FileOpChmod)
+			} else {
+				add(path, FileOpWrite)
+			}
+		case state.mode != old.mode:
+			add(path, FileOpChmod)
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			add(path, FileOpRemove)
+		}
+	}
+
+	// Parents before children, and a stable order for tests
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Path < events[j].Path })
+	return events
+}
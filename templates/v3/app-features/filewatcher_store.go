@@ -0,0 +1,392 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// snapshotSaveDelay batches snapshot writes after changes
+const snapshotSaveDelay = time.Second
+
+// maxHeldEvents bounds the events held for a frontend that is not ready yet;
+// beyond it they are replaced by an overflow event per watch
+const maxHeldEvents = 10000
+
+// storedWatch is one entry of the persisted watch set
+type storedWatch struct {
+	ID      string       `json:"id"`
+	Path    string       `json:"path"`
+	Options WatchOptions `json:"options"`
+}
+
+// storedState is the on-disk form of fileState
+type storedState struct {
+	IsDir   bool   `json:"dir,omitempty"`
+	Size    int64  `json:"size,omitempty"`
+	ModTime int64  `json:"mtime,omitempty"`
+	Mode    uint32 `json:"mode"`
+	Hash    string `json:"hash,omitempty"`
+}
+
+// watchStore persists the watch set and a snapshot of each watch so changes
+// made while the app was closed can be reported on the next launch
+type watchStore struct {
+	dir string
+}
+
+// watchStoreDir returns where watches and snapshots are persisted
+func watchStoreDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".{{PROJECT_NAME}}", "watches"), nil
+}
+
+func newWatchStore(dir string) *watchStore {
+	return &watchStore{dir: dir}
+}
+
+// loadWatches reads the persisted watch set, ignoring a missing or corrupt file
+func (s *watchStore) loadWatches() []storedWatch {
+	data, err := os.ReadFile(filepath.Join(s.dir, "watches.json"))
+	if err != nil {
+		return nil
+	}
+	var watches []storedWatch
+	if err := json.Unmarshal(data, &watches); err != nil {
+		return nil
+	}
+	return watches
+}
+
+// saveWatches persists the watch set
+func (s *watchStore) saveWatches(watches []storedWatch) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(watches, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, "watches.json"), data, 0600)
+}
+
+// snapshotPath returns the snapshot file for a watch ID
+func (s *watchStore) snapshotPath(id string) string {
+	return filepath.Join(s.dir, "snapshot-"+id+".json")
+}
+
+// loadSnapshot reads a watch's snapshot, reporting false if there is none
+func (s *watchStore) loadSnapshot(id string) (map[string]fileState, bool) {
+	data, err := os.ReadFile(s.snapshotPath(id))
+	if err != nil {
+		return nil, false
+	}
+	var stored map[string]storedState
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, false
+	}
+
+	states := make(map[string]fileState, len(stored))
+	for path, entry := range stored {
+		state := fileState{
+			isDir: entry.IsDir,
+			size:  entry.Size,
+			mode:  fs.FileMode(entry.Mode),
+		}
+		if entry.ModTime != 0 {
+			state.modTime = time.Unix(0, entry.ModTime)
+		}
+		if hash, err := hex.DecodeString(entry.Hash); err == nil && len(hash) == len(state.hash) {
+			copy(state.hash[:], hash)
+			state.hashed = true
+		}
+		states[path] = state
+	}
+	return states, true
+}
+
+// saveSnapshot persists a watch's snapshot, writing to a temporary file first
+// so a crash cannot leave a truncated snapshot behind
+func (s *watchStore) saveSnapshot(id string, states map[string]fileState) error {
+	stored := make(map[string]storedState, len(states))
+	for path, state := range states {
+		entry := storedState{IsDir: state.isDir, Size: state.size, Mode: uint32(state.mode)}
+		if !state.modTime.IsZero() {
+			entry.ModTime = state.modTime.UnixNano()
+		}
+		if state.hashed {
+			entry.Hash = hex.EncodeToString(state.hash[:])
+		}
+		stored[path] = entry
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	tmp := s.snapshotPath(id) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.snapshotPath(id))
+}
+
+// removeSnapshot deletes a watch's snapshot
+func (s *watchStore) removeSnapshot(id string) {
+	os.Remove(s.snapshotPath(id))
+}
+
+// restore re-establishes the persisted watches with their IDs and queues
+// events for everything that changed since their snapshots were taken.
+// Watches whose path is missing, e.g. an unmounted share, stay persisted.
+func (w *FileWatcher) restore(store *watchStore) {
+	w.mu.Lock()
+	w.store = store
+	w.mu.Unlock()
+
+	for _, stored := range store.loadWatches() {
+		var seq uint64
+		if _, err := fmt.Sscanf(stored.ID, "watch-%d", &seq); err != nil {
+			continue
+		}
+		w.mu.Lock()
+		if seq > w.nextID {
+			w.nextID = seq
+		}
+		w.mu.Unlock()
+
+		previous, hasSnapshot := store.loadSnapshot(stored.ID)
+		watch, err := w.add(stored.Path, stored.Options, stored.ID, seq)
+		if err != nil {
+			w.logError(fmt.Errorf("could not restore watch of %s: %w", stored.Path, err))
+			w.mu.Lock()
+			w.missing = append(w.missing, stored)
+			w.mu.Unlock()
+			continue
+		}
+		if !hasSnapshot {
+			continue
+		}
+
+		w.mu.Lock()
+		now := time.Now()
+		for _, event := range diffFileStates(watch.id, previous, watch.snapshot) {
+			w.debounce.add(event, false, now)
+		}
+		w.mu.Unlock()
+	}
+
+	w.mu.Lock()
+	w.saveWatches()
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Hold keeps events from being emitted until Ready is called. Call it before
+// restore when the frontend may not be listening yet, so the offline changes
+// restore finds are not lost.
+func (w *FileWatcher) Hold() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.holding = true
+}
+
+// Ready stops holding events and returns the ones held since Hold, for the
+// caller to deliver; later events are emitted as they happen
+func (w *FileWatcher) Ready() []FileEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	held := w.held
+	if held == nil {
+		held = []FileEvent{}
+	}
+	w.holding, w.held, w.heldFull = false, nil, false
+	return held
+}
+
+// hold queues events while the watcher is held, reporting whether it did
+func (w *FileWatcher) hold(events []FileEvent) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.holding {
+		return false
+	}
+	if w.heldFull {
+		// The overflows already held cover everything since
+		return true
+	}
+	w.held = append(w.held, events...)
+	if len(w.held) >= maxHeldEvents {
+		w.held, w.heldFull = w.held[:0], true
+		for _, info := range w.listLocked() {
+			w.held = append(w.held, FileEvent{WatchID: info.ID, Path: info.Path, Op: FileOpOverflow})
+		}
+	}
+	return true
+}
+
+// saveWatches persists the active and missing watches; w.mu must be held
+func (w *FileWatcher) saveWatches() {
+	if w.store == nil {
+		return
+	}
+
+	watches := append([]storedWatch{}, w.missing...)
+	for _, info := range w.listLocked() {
+		watches = append(watches, storedWatch{ID: info.ID, Path: info.Path, Options: info.Options})
+	}
+	if err := w.store.saveWatches(watches); err != nil {
+		w.logError(err)
+	}
+}
+
+// forget removes a watch from the persisted set; w.mu must be held
+func (w *FileWatcher) forget(id string) {
+	if w.store == nil {
+		return
+	}
+	w.store.removeSnapshot(id)
+	w.saveWatches()
+}
+
+// forgetMissing drops persisted watches that could not be restored, by ID or
+// path; w.mu must be held
+func (w *FileWatcher) forgetMissing(idOrPath, absPath string) bool {
+	found := false
+	kept := w.missing[:0]
+	for _, stored := range w.missing {
+		if stored.ID == idOrPath || stored.Path == absPath {
+			found = true
+			if w.store != nil {
+				w.store.removeSnapshot(stored.ID)
+			}
+			continue
+		}
+		kept = append(kept, stored)
+	}
+	w.missing = kept
+	if found {
+		w.saveWatches()
+	}
+	return found
+}
+
+// track applies sent events to the snapshots of persisted watches; w.mu must be held
+func (w *FileWatcher) track(events []FileEvent) {
+	for _, event := range events {
+		watch, ok := w.watches[event.WatchID]
+		if !ok || watch.snapshot == nil {
+			continue
+		}
+		watch.dirty = true
+
+		switch event.Op {
+		case FileOpOverflow:
+			watch.rescan = true
+		case FileOpRemove, FileOpRename:
+			deleteSnapshotTree(watch.snapshot, event.Path)
+		default:
+			info, err := os.Lstat(event.Path)
+			if err != nil {
+				deleteSnapshotTree(watch.snapshot, event.Path)
+				continue
+			}
+			watch.snapshot[event.Path] = watch.state(event.Path, info)
+		}
+	}
+}
+
+// dirtySnapshots reports whether any snapshot has unsaved changes
+func (w *FileWatcher) dirtySnapshots() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, watch := range w.watches {
+		if watch.dirty {
+			return true
+		}
+	}
+	return false
+}
+
+// saveSnapshots writes changed snapshots, rescanning watches that overflowed
+func (w *FileWatcher) saveSnapshots() {
+	type pendingSnapshot struct {
+		watch  *fileWatch
+		states map[string]fileState
+	}
+
+	w.mu.Lock()
+	store := w.store
+	var pending []pendingSnapshot
+	for _, watch := range w.watches {
+		if !watch.dirty {
+			continue
+		}
+		watch.dirty = false
+		snapshot := pendingSnapshot{watch: watch}
+		if !watch.rescan {
+			snapshot.states = cloneFileStates(watch.snapshot)
+		}
+		pending = append(pending, snapshot)
+	}
+	w.mu.Unlock()
+
+	if store == nil {
+		return
+	}
+	for _, snapshot := range pending {
+		if snapshot.states == nil {
+			states, err := snapshot.watch.scan()
+			if err != nil {
+				w.logError(err)
+				continue
+			}
+			w.mu.Lock()
+			snapshot.watch.snapshot = states
+			snapshot.watch.rescan = false
+			snapshot.states = cloneFileStates(states)
+			w.mu.Unlock()
+		}
+		if err := store.saveSnapshot(snapshot.watch.id, snapshot.states); err != nil {
+			w.logError(err)
+		}
+	}
+}
+
+// deleteSnapshotTree removes path and everything below it
+func deleteSnapshotTree(states map[string]fileState, path string) {
+	delete(states, path)
+	prefix := path + string(filepath.Separator)
+	for entry := range states {
+		if strings.HasPrefix(entry, prefix) {
+			delete(states, entry)
+		}
+	}
+}
+
+// cloneFileStates copies a snapshot
+func cloneFileStates(states map[string]fileState) map[string]fileState {
+	clone := make(map[string]fileState, len(states))
+	for path, state := range states {
+		clone[path] = state
+	}
+	return clone
+}
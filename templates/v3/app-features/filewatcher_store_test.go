@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchStoreSnapshotRoundTrip(t *testing.T) {
+	store := newWatchStore(t.TempDir())
+	modTime := time.Unix(1700000000, 123456789)
+	states := map[string]fileState{
+		"/w/dir":  {isDir: true, mode: os.ModeDir | 0755},
+		"/w/file": {size: 42, modTime: modTime, mode: 0644, hash: [32]byte{7}, hashed: true},
+		"/w/big":  {size: 1 << 30, modTime: modTime, mode: 0600},
+	}
+
+	if err := store.saveSnapshot("watch-1", states); err != nil {
+		t.Fatal(err)
+	}
+	loaded, ok := store.loadSnapshot("watch-1")
+	if !ok {
+		t.Fatal("snapshot not found")
+	}
+	if len(loaded) != len(states) {
+		t.Fatalf("loaded %d entries, want %d", len(loaded), len(states))
+	}
+	for path, want := range states {
+		got := loaded[path]
+		if got.isDir != want.isDir || got.size != want.size || got.mode != want.mode ||
+			!got.modTime.Equal(want.modTime) || got.hash != want.hash || got.hashed != want.hashed {
+			t.Errorf("%s = %+v, want %+v", path, got, want)
+		}
+	}
+	if events := diffFileStates("watch-1", states, loaded); len(events) != 0 {
+		t.Errorf("round trip changed state: %+v", events)
+	}
+
+	store.removeSnapshot("watch-1")
+	if _, ok := store.loadSnapshot("watch-1"); ok {
+		t.Error("snapshot still present after removeSnapshot")
+	}
+}
+
+// collectFileEvents gathers events until none arrive for quiet
+func collectFileEvents(events chan FileEvent, quiet time.Duration) []FileEvent {
+	var got []FileEvent
+	for {
+		select {
+		case event := <-events:
+			got = append(got, event)
+		case <-time.After(quiet):
+			return got
+		}
+	}
+}
+
+// newPersistentWatcher starts a watcher restored from store
+func newPersistentWatcher(t *testing.T, store *watchStore) (*FileWatcher, chan FileEvent) {
+	t.Helper()
+	w, events := newTestFileWatcher(t)
+	w.restore(store)
+	return w, events
+}
+
+func TestFileWatcherRestoresWatchesAndOfflineChanges(t *testing.T) {
+	store := newWatchStore(t.TempDir())
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{"kept.txt": "a", "edited.txt": "a", "deleted.txt": "a"})
+
+	first, events := newPersistentWatcher(t, store)
+	id, err := first.Watch(root, WatchOptions{Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Changes seen while running are part of the saved snapshot
+	online := filepath.Join(root, "online.txt")
+	os.WriteFile(online, []byte("a"), 0644)
+	waitForFileEvent(t, events, online)
+	first.Close()
+
+	// Changes made while the app is closed
+	edited := filepath.Join(root, "edited.txt")
+	os.WriteFile(edited, []byte("changed"), 0644)
+	os.Remove(filepath.Join(root, "deleted.txt"))
+	makeTree(t, root, map[string]string{"sub/created.txt": "a"})
+
+	second, events := newPersistentWatcher(t, store)
+	if info := second.List(); len(info) != 1 || info[0].ID != id || info[0].Path != root || !info[0].Recursive {
+		t.Fatalf("restored watches = %+v, want %s on %s", info, id, root)
+	}
+
+	want := map[string]string{
+		edited:                                    FileOpWrite,
+		filepath.Join(root, "deleted.txt"):        FileOpRemove,
+		filepath.Join(root, "sub"):                FileOpCreate,
+		filepath.Join(root, "sub", "created.txt"): FileOpCreate,
+	}
+	got := collectFileEvents(events, 500*time.Millisecond)
+	if len(got) != len(want) {
+		t.Errorf("offline changes = %+v, want %d", got, len(want))
+	}
+	for _, event := range got {
+		if want[event.Path] != event.Op || event.WatchID != id {
+			t.Errorf("offline change %+v, want %q from %s", event, want[event.Path], id)
+		}
+	}
+
+	// New IDs continue after the restored ones
+	other, err := second.Watch(t.TempDir(), WatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == id {
+		t.Errorf("new watch reused restored ID %s", id)
+	}
+}
+
+func TestFileWatcherHoldsOfflineChangesUntilReady(t *testing.T) {
+	store := newWatchStore(t.TempDir())
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{"edited.txt": "a"})
+
+	first, _ := newPersistentWatcher(t, store)
+	id, err := first.Watch(root, WatchOptions{Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+
+	edited := filepath.Join(root, "edited.txt")
+	os.WriteFile(edited, []byte("changed"), 0644)
+
+	// Restored at startup, before the frontend listens
+	second, events := newTestFileWatcher(t)
+	second.Hold()
+	second.restore(store)
+	expectNoFileEvent(t, events)
+
+	held := second.Ready()
+	if want := (FileEvent{WatchID: id, Path: edited, Op: FileOpWrite}); len(held) != 1 || held[0] != want {
+		t.Errorf("held = %+v, want [%+v]", held, want)
+	}
+
+	created := filepath.Join(root, "created.txt")
+	os.WriteFile(created, []byte("a"), 0644)
+	if event := waitForFileEvent(t, events, created); event.Op != FileOpCreate {
+		t.Errorf("event after Ready = %+v, want create", event)
+	}
+}
+
+func TestFileWatcherHeldEventsOverflow(t *testing.T) {
+	root := t.TempDir()
+	w, _ := newTestFileWatcher(t)
+	id, err := w.Watch(root, WatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Hold()
+	for i := 0; i <= maxHeldEvents; i++ {
+		w.send([]FileEvent{{WatchID: id, Path: filepath.Join(root, fmt.Sprint(i)), Op: FileOpCreate}})
+	}
+	held := w.Ready()
+	if want := (FileEvent{WatchID: id, Path: root, Op: FileOpOverflow}); len(held) != 1 || held[0] != want {
+		t.Errorf("held %d events, want only %+v", len(held), want)
+	}
+}
+
+func TestFileWatcherKeepsMissingWatches(t *testing.T) {
+	store := newWatchStore(t.TempDir())
+	missing := filepath.Join(t.TempDir(), "unmounted")
+	store.saveWatches([]storedWatch{{ID: "watch-7", Path: missing, Options: WatchOptions{Recursive: true}}})
+
+	w, _ := newPersistentWatcher(t, store)
+	if info := w.List(); len(info) != 0 {
+		t.Errorf("List() = %+v, want no active watches", info)
+	}
+	if stored := store.loadWatches(); len(stored) != 1 || stored[0].ID != "watch-7" {
+		t.Errorf("persisted watches = %+v, want the missing watch kept", stored)
+	}
+
+	if err := w.Unwatch("watch-7"); err != nil {
+		t.Fatalf("Unwatch missing watch: %v", err)
+	}
+	if stored := store.loadWatches(); len(stored) != 0 {
+		t.Errorf("persisted watches = %+v after Unwatch, want none", stored)
+	}
+}
+
+func TestFileWatcherUnwatchForgetsPersistedWatch(t *testing.T) {
+	store := newWatchStore(t.TempDir())
+	w, _ := newPersistentWatcher(t, store)
+
+	id, err := w.Watch(t.TempDir(), WatchOptions{Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := w.Watch(w.List()[0].Path, WatchOptions{Recursive: true}); again != id {
+		t.Errorf("duplicate Watch returned %s, want %s", again, id)
+	}
+	w.Unwatch(id)
+	w.Close()
+
+	if stored := store.loadWatches(); len(stored) != 0 {
+		t.Errorf("persisted watches = %+v, want none", stored)
+	}
+	if _, ok := store.loadSnapshot(id); ok {
+		t.Error("snapshot kept after Unwatch")
+	}
+}
+
+func TestFileWatcherWithoutStoreDoesNotPersist(t *testing.T) {
+	w, err := NewFileWatcher(context.Background(), func(string, interface{}) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if _, err := w.Watch(t.TempDir(), WatchOptions{Recursive: true}); err != nil {
+		t.Fatal(err)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, watch := range w.watches {
+		if watch.snapshot != nil {
+			t.Error("snapshot kept without a store")
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestFileWatcher(t *testing.T) (*FileWatcher, chan FileEvent) {
+	t.Helper()
+	events := make(chan FileEvent, 100)
+	w, err := NewFileWatcher(context.Background(), func(name string, data interface{}) {
+		switch name {
+		case FileEventName:
+			events <- data.(FileEvent)
+		case FileBatchEventName:
+			for _, event := range data.([]FileEvent) {
+				events <- event
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w, events
+}
+
+// waitForFileEvent returns the first event matching path, failing after a timeout
+func waitForFileEvent(t *testing.T, events chan FileEvent, path string) FileEvent {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Path == path {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("no event for %s", path)
+		}
+	}
+}
+
+// expectNoFileEvent fails if any event arrives within a short window
+func expectNoFileEvent(t *testing.T, events chan FileEvent) {
+	t.Helper()
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %+v", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestFileWatcherRecursiveAddsNewDirectories(t *testing.T) {
+	root := t.TempDir()
+	w, events := newTestFileWatcher(t)
+
+	id, err := w.Watch(root, WatchOptions{Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if event := waitForFileEvent(t, events, sub); event.Op != FileOpCreate || event.WatchID != id {
+		t.Errorf("directory event = %+v, want create from %s", event, id)
+	}
+
+	file := filepath.Join(sub, "new.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if event := waitForFileEvent(t, events, file); event.WatchID != id {
+		t.Errorf("file event = %+v, want watch %s", event, id)
+	}
+}
+
+func TestFileWatcherFileWatchIgnoresSiblings(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(target, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, events := newTestFileWatcher(t)
+
+	if _, err := w.Watch(target, WatchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "other.json"), []byte("{}"), 0644)
+	expectNoFileEvent(t, events)
+
+	os.WriteFile(target, []byte(`{"a":1}`), 0644)
+	if event := waitForFileEvent(t, events, target); event.Op != FileOpWrite {
+		t.Errorf("event = %+v, want write", event)
+	}
+}
+
+func TestFileWatcherAtomicSaveIsWrite(t *testing.T) {
+	for name, watchFile := range map[string]bool{"directory": false, "file": true} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			target := filepath.Join(dir, "notes.txt")
+			if err := os.WriteFile(target, []byte("old"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			w, events := newTestFileWatcher(t)
+
+			path := dir
+			if watchFile {
+				path = target
+			}
+			if _, err := w.Watch(path, WatchOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			// Editors write a temporary file and rename it over the original
+			temp := filepath.Join(dir, ".notes.txt.tmp")
+			if err := os.WriteFile(temp, []byte("new"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Rename(temp, target); err != nil {
+				t.Fatal(err)
+			}
+			if event := waitForFileEvent(t, events, target); event.Op != FileOpWrite {
+				t.Errorf("event = %+v, want write", event)
+			}
+		})
+	}
+}
+
+func TestFileWatcherUnwatch(t *testing.T) {
+	root := t.TempDir()
+	w, events := newTestFileWatcher(t)
+
+	id, err := w.Watch(root, WatchOptions{Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.List(); len(got) != 1 || got[0].ID != id || got[0].Path != root || !got[0].Recursive {
+		t.Fatalf("List() = %+v", got)
+	}
+
+	if err := w.Unwatch(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Unwatch(id); err != ErrWatchNotFound {
+		t.Errorf("second Unwatch error = %v, want ErrWatchNotFound", err)
+	}
+
+	os.WriteFile(filepath.Join(root, "after.txt"), []byte("x"), 0644)
+	expectNoFileEvent(t, events)
+}
+
+func TestFileWatcherSharedDirectories(t *testing.T) {
+	root := t.TempDir()
+	w, events := newTestFileWatcher(t)
+
+	first, _ := w.Watch(root, WatchOptions{Recursive: true})
+	second, err := w.Watch(root, WatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Removing one watch must keep the directory watched for the other
+	w.Unwatch(first)
+
+	file := filepath.Join(root, "shared.txt")
+	os.WriteFile(file, []byte("x"), 0644)
+	if event := waitForFileEvent(t, events, file); event.WatchID != second {
+		t.Errorf("event = %+v, want watch %s", event, second)
+	}
+}
+
+func TestFileWatcherClose(t *testing.T) {
+	w, _ := newTestFileWatcher(t)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Watch(t.TempDir(), WatchOptions{Recursive: true}); err != ErrWatcherClosed {
+		t.Errorf("Watch after Close error = %v, want ErrWatcherClosed", err)
+	}
+}
@@ -0,0 +1,15 @@
+// Single Instance Helper
+import { Events } from '@wailsio/runtime'
+
+// Called whenever the app is launched again while already running.
+// The window has already been restored and focused by the backend.
+export function onSecondInstance(listener) {
+  return Events.On('second-instance', (event) => listener(event.data))
+}
+
+// Example usage
+export function setupSecondInstance() {
+  onSecondInstance(({ args, workingDir }) => {
+    console.log('Launched again with:', args, 'from', workingDir)
+  })
+}
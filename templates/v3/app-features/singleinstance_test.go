@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInstanceLockExcludesSecondHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instance.lock")
+
+	first, err := lockInstanceFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second, err := lockInstanceFile(path); !errors.Is(err, errLocked) {
+		if second != nil {
+			second.Close()
+		}
+		t.Fatalf("second lockInstanceFile error = %v, want errLocked", err)
+	}
+
+	// A holder that exits, even by crashing, releases the lock
+	unlockFile(first)
+	first.Close()
+	again, err := lockInstanceFile(path)
+	if err != nil {
+		t.Fatalf("lockInstanceFile after release = %v, want the lock", err)
+	}
+	unlockFile(again)
+	again.Close()
+}
+
+func TestForwardToRunningInstance(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "instance.sock")
+
+	received := make(chan SecondInstanceMessage, 1)
+	hooks := secondInstanceHooks
+	secondInstanceHooks = []func(SecondInstanceMessage){func(msg SecondInstanceMessage) {
+		received <- msg
+	}}
+	t.Cleanup(func() { secondInstanceHooks = hooks })
+
+	if err := listenForSecondInstances(socketPath); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { instanceListener.Close() })
+
+	if err := forwardToRunningInstance(socketPath); err != nil {
+		t.Fatalf("forwardToRunningInstance = %v", err)
+	}
+
+	workingDir, _ := os.Getwd()
+	select {
+	case msg := <-received:
+		if !reflect.DeepEqual(msg.Args, os.Args[1:]) || msg.WorkingDir != workingDir {
+			t.Errorf("hooks got %+v, want args %q in %q", msg, os.Args[1:], workingDir)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("forwarded launch did not reach the second instance hooks")
+	}
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckPrivateDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "runtime")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPrivateDir(dir); err != nil {
+		t.Fatalf("checkPrivateDir(0700) = %v, want nil", err)
+	}
+
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPrivateDir(dir); err == nil {
+		t.Error("checkPrivateDir(0755) = nil, want an error")
+	}
+
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPrivateDir(link); err == nil {
+		t.Error("checkPrivateDir(symlink) = nil, want an error")
+	}
+}
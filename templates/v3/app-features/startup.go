@@ -1,190 +1,220 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
-	"golang.org/x/sys/windows/registry"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
 )
 
-// EnableStartup enables the app to launch on system startup
-func (a *App) EnableStartup() error {
-	switch runtime.GOOS {
-	case "windows":
-		return a.enableStartupWindows()
-	case "darwin":
-		return a.enableStartupMacOS()
-	case "linux":
-		return a.enableStartupLinux()
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
-	}
+// Autostarter registers the app to launch when the user logs in.
+// Each platform provides its implementation in startup_<os>.go.
+type Autostarter interface {
+	Enable() error
+	Disable() error
+	IsEnabled() (bool, error)
 }
 
-// DisableStartup disables the app from launching on system startup
-func (a *App) DisableStartup() error {
-	switch runtime.GOOS {
-	case "windows":
-		return a.disableStartupWindows()
-	case "darwin":
-		return a.disableStartupMacOS()
-	case "linux":
-		return a.disableStartupLinux()
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
-	}
+// StartupMode selects the launch mechanism on platforms that offer several
+type StartupMode string
+
+const (
+	// StartupModeAutostart uses the desktop session's login items (default)
+	StartupModeAutostart StartupMode = "autostart"
+	// StartupModeSystemd installs a systemd user service with a restart policy (Linux)
+	StartupModeSystemd StartupMode = "systemd"
+)
+
+// AutostartFlag is added to the login command so the app knows the OS launched it
+const AutostartFlag = "--autostart"
+
+// StartupOptions configures how the app is launched at login
+type StartupOptions struct {
+	// Mode selects autostart or a systemd user service
+	Mode StartupMode `json:"mode"`
+	// Target is the systemd target that pulls in the service (graphical-session.target if empty)
+	Target string `json:"target"`
+	// Args are passed to the app after AutostartFlag, e.g. "--profile=work"
+	Args []string `json:"args"`
+	// Delay postpones the launch by this many seconds (Linux)
+	Delay int `json:"delay"`
+	// HideWhenDisabled keeps the entry with Hidden=true instead of deleting it (Linux)
+	HideWhenDisabled bool `json:"hideWhenDisabled"`
 }
 
-// IsStartupEnabled checks if startup is enabled
-func (a *App) IsStartupEnabled() (bool, error) {
-	switch runtime.GOOS {
-	case "windows":
-		return a.isStartupEnabledWindows()
-	case "darwin":
-		return a.isStartupEnabledMacOS()
-	case "linux":
-		return a.isStartupEnabledLinux()
-	default:
-		return false, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
-	}
+// StartupSettings are the user's startup preferences, persisted across runs
+type StartupSettings struct {
+	Mode StartupMode `json:"mode"`
+	// StartHidden keeps the window hidden, leaving only the tray icon, when launched at login
+	StartHidden bool `json:"startHidden"`
 }
 
-// Windows implementation
-func (a *App) enableStartupWindows() error {
-	exePath, err := os.Executable()
-	if err != nil {
-		return err
-	}
-	
-	key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Run`, registry.SET_VALUE)
-	if err != nil {
-		return err
-	}
-	defer key.Close()
-	
-	return key.SetStringValue("{{PROJECT_NAME}}", exePath)
+// Metadata used in the OS autostart configuration
+const (
+	startupAppName    = "{{PROJECT_NAME}}"
+	startupAppComment = "Start {{PROJECT_NAME}} when you log in"
+	startupAppIcon    = "{{PROJECT_NAME}}"
+)
+
+// defaultStartupOptions are used by EnableStartup and DisableStartup;
+// Mode is taken from the saved StartupSettings
+var defaultStartupOptions = StartupOptions{Mode: StartupModeAutostart}
+
+// defaultStartupSettings apply until the user changes them
+var defaultStartupSettings = StartupSettings{Mode: StartupModeAutostart, StartHidden: true}
+
+// launchArgs returns the arguments the OS passes to the app at login
+func launchArgs(options StartupOptions) []string {
+	return append([]string{AutostartFlag}, options.Args...)
 }
 
-func (a *App) disableStartupWindows() error {
-	key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Run`, registry.SET_VALUE)
+// LaunchedAtLogin reports whether the OS started the app via its startup entry
+func LaunchedAtLogin() bool {
+	for _, arg := range os.Args[1:] {
+		if arg == AutostartFlag {
+			return true
+		}
+	}
+	return false
+}
+
+// StartHiddenAtLogin reports whether the window should start hidden, which is
+// the case when launched at login and the user has not opted out
+func StartHiddenAtLogin() bool {
+	return LaunchedAtLogin() && loadStartupSettings().StartHidden
+}
+
+// startupSettingsPath returns the path to the startup settings file
+func startupSettingsPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer key.Close()
-	
-	return key.DeleteValue("{{PROJECT_NAME}}")
+	return filepath.Join(homeDir, ".{{PROJECT_NAME}}", "startup.json"), nil
 }
 
-func (a *App) isStartupEnabledWindows() (bool, error) {
-	key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Run`, registry.QUERY_VALUE)
+// loadStartupSettings returns the saved settings, or the defaults if none are saved
+func loadStartupSettings() StartupSettings {
+	settings := defaultStartupSettings
+
+	settingsPath, err := startupSettingsPath()
 	if err != nil {
-		return false, nil
+		return settings
 	}
-	defer key.Close()
-	
-	_, _, err = key.GetStringValue("{{PROJECT_NAME}}")
-	return err == nil, nil
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return settings
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return defaultStartupSettings
+	}
+	if settings.Mode == "" {
+		settings.Mode = StartupModeAutostart
+	}
+	return settings
 }
 
-// macOS implementation
-func (a *App) enableStartupMacOS() error {
-	homeDir, err := os.UserHomeDir()
+// saveStartupSettings persists the user's startup settings
+func saveStartupSettings(settings StartupSettings) error {
+	settingsPath, err := startupSettingsPath()
 	if err != nil {
 		return err
 	}
-	
-	launchAgentsDir := filepath.Join(homeDir, "Library/LaunchAgents")
-	if err := os.MkdirAll(launchAgentsDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
 		return err
 	}
-	
-	plistPath := filepath.Join(launchAgentsDir, "com.{{PROJECT_NAME}}.plist")
-	exePath, err := os.Executable()
-	if err != nil {
-		return err
-	}
-	
-	plistContent := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
-<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
-<plist version="1.0">
-<dict>
-	<key>Label</key>
-	<string>com.{{PROJECT_NAME}}</string>
-	<key>ProgramArguments</key>
-	<array>
-		<string>%s</string>
-	</array>
-	<key>RunAtLoad</key>
-	<true/>
-</dict>
-</plist>`, exePath)
-	
-	return os.WriteFile(plistPath, []byte(plistContent), 0644)
-}
-
-func (a *App) disableStartupMacOS() error {
-	homeDir, err := os.UserHomeDir()
+
+	data, err := json.MarshalIndent(settings, "", "  ")
 	if err != nil {
 		return err
 	}
-	plistPath := filepath.Join(homeDir, "Library/LaunchAgents/com.{{PROJECT_NAME}}.plist")
-	return os.Remove(plistPath)
+	return os.WriteFile(settingsPath, data, 0644)
 }
 
-func (a *App) isStartupEnabledMacOS() (bool, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return false, err
-	}
-	plistPath := filepath.Join(homeDir, "Library/LaunchAgents/com.{{PROJECT_NAME}}.plist")
-	_, err = os.Stat(plistPath)
-	return err == nil, nil
+// startupOptions returns the default options with the user's saved mode
+func startupOptions() StartupOptions {
+	options := defaultStartupOptions
+	options.Mode = loadStartupSettings().Mode
+	return options
 }
 
-// Linux implementation
-func (a *App) enableStartupLinux() error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
+// StartupService manages launching the app at login
+type StartupService struct{}
+
+// ServiceStartup has nothing to prepare; settings are read on each call
+func (s *StartupService) ServiceStartup(ctx context.Context, options application.ServiceOptions) error {
+	return nil
+}
+
+// ServiceShutdown has nothing to release; settings are written on every change
+func (s *StartupService) ServiceShutdown() error {
+	return nil
+}
+
+// EnableStartup enables the app to launch on system startup
+func (s *StartupService) EnableStartup() error {
+	return newAutostarter(startupOptions()).Enable()
+}
+
+// SetStartupMode selects how EnableStartup launches the app, disabling the previous mode
+func (s *StartupService) SetStartupMode(mode StartupMode) error {
+	if mode != StartupModeAutostart && mode != StartupModeSystemd {
+		return fmt.Errorf("unknown startup mode: %s", mode)
 	}
-	
-	autostartDir := filepath.Join(homeDir, ".config/autostart")
-	if err := os.MkdirAll(autostartDir, 0755); err != nil {
-		return err
+	if mode == StartupModeSystemd && runtime.GOOS != "linux" {
+		return fmt.Errorf("startup mode %s is not supported on %s", mode, runtime.GOOS)
 	}
-	
-	desktopPath := filepath.Join(autostartDir, "{{PROJECT_NAME}}.desktop")
-	exePath, err := os.Executable()
-	if err != nil {
+	settings := loadStartupSettings()
+	if mode == settings.Mode {
+		return nil
+	}
+
+	enabled, _ := s.IsStartupEnabled()
+	if err := s.DisableStartup(); err != nil {
 		return err
 	}
-	
-	desktopContent := fmt.Sprintf(`[Desktop Entry]
-Type=Application
-Name=My Weather App 2
-Exec=%s
-Terminal=false
-X-GNOME-Autostart-enabled=true`, exePath)
-	
-	return os.WriteFile(desktopPath, []byte(desktopContent), 0644)
-}
-
-func (a *App) disableStartupLinux() error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
+	settings.Mode = mode
+	if err := saveStartupSettings(settings); err != nil {
 		return err
 	}
-	autostartPath := filepath.Join(homeDir, ".config/autostart/{{PROJECT_NAME}}.desktop")
-	return os.Remove(autostartPath)
+	if enabled {
+		return s.EnableStartup()
+	}
+	return nil
 }
 
-func (a *App) isStartupEnabledLinux() (bool, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return false, err
-	}
-	autostartPath := filepath.Join(homeDir, ".config/autostart/{{PROJECT_NAME}}.desktop")
-	_, err = os.Stat(autostartPath)
-	return err == nil, nil
+// EnableStartupWithOptions enables launching on system startup with custom options
+func (s *StartupService) EnableStartupWithOptions(options StartupOptions) error {
+	return newAutostarter(options).Enable()
+}
+
+// DisableStartup disables the app from launching on system startup
+func (s *StartupService) DisableStartup() error {
+	return newAutostarter(startupOptions()).Disable()
+}
+
+// IsStartupEnabled checks if startup is enabled
+func (s *StartupService) IsStartupEnabled() (bool, error) {
+	return newAutostarter(startupOptions()).IsEnabled()
+}
+
+// WasLaunchedAtLogin reports whether this run was started by the OS at login
+func (s *StartupService) WasLaunchedAtLogin() bool {
+	return LaunchedAtLogin()
+}
+
+// GetStartupSettings returns the user's startup settings
+func (s *StartupService) GetStartupSettings() StartupSettings {
+	return loadStartupSettings()
+}
+
+// SetStartHiddenAtLogin sets whether a login launch starts hidden in the tray
+func (s *StartupService) SetStartHiddenAtLogin(hidden bool) error {
+	settings := loadStartupSettings()
+	settings.StartHidden = hidden
+	return saveStartupSettings(settings)
 }
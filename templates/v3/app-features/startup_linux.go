@@ -0,0 +1,267 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// xdgAutostarter uses an XDG autostart desktop entry
+type xdgAutostarter struct {
+	options StartupOptions
+}
+
+func newAutostarter(options StartupOptions) Autostarter {
+	if options.Mode == StartupModeSystemd {
+		return systemdAutostarter{options: options}
+	}
+	return xdgAutostarter{options: options}
+}
+
+// desktopPath returns the autostart entry location, honoring XDG_CONFIG_HOME
+func (xdgAutostarter) desktopPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(configHome) {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configHome, "autostart", startupAppName+".desktop"), nil
+}
+
+// desktopEntry renders the autostart entry for the given executable
+func (x xdgAutostarter) desktopEntry(exePath string, hidden bool) string {
+	command := []string{desktopExecArg(exePath)}
+	for _, arg := range launchArgs(x.options) {
+		command = append(command, desktopExecArg(arg))
+	}
+
+	var entry strings.Builder
+	entry.WriteString("[Desktop Entry]\n")
+	entry.WriteString("Type=Application\n")
+	fmt.Fprintf(&entry, "Name=%s\n", desktopValue(startupAppName))
+	fmt.Fprintf(&entry, "Comment=%s\n", desktopValue(startupAppComment))
+	fmt.Fprintf(&entry, "Icon=%s\n", desktopValue(startupAppIcon))
+	fmt.Fprintf(&entry, "Exec=%s\n", strings.Join(command, " "))
+	entry.WriteString("Terminal=false\n")
+	fmt.Fprintf(&entry, "Hidden=%t\n", hidden)
+	fmt.Fprintf(&entry, "X-GNOME-Autostart-enabled=%t\n", !hidden)
+	if x.options.Delay > 0 {
+		fmt.Fprintf(&entry, "X-GNOME-Autostart-Delay=%d\n", x.options.Delay)
+	}
+	return entry.String()
+}
+
+// write renders and stores the desktop entry
+func (x xdgAutostarter) write(hidden bool) error {
+	desktopPath, err := x.desktopPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(desktopPath), 0755); err != nil {
+		return err
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(desktopPath, []byte(x.desktopEntry(exePath, hidden)), 0644)
+}
+
+func (x xdgAutostarter) Enable() error {
+	return x.write(false)
+}
+
+func (x xdgAutostarter) Disable() error {
+	if x.options.HideWhenDisabled {
+		return x.write(true)
+	}
+
+	desktopPath, err := x.desktopPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(desktopPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (x xdgAutostarter) IsEnabled() (bool, error) {
+	desktopPath, err := x.desktopPath()
+	if err != nil {
+		return false, err
+	}
+
+	file, err := os.Open(desktopPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	// A hidden entry is treated as deleted by the desktop
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "Hidden=true" {
+			return false, nil
+		}
+	}
+	return true, scanner.Err()
+}
+
+// systemdAutostarter installs a systemd user service, which restarts the app on
+// failure and logs to journald. The service runs as part of the graphical
+// session so it starts with a display and stops when the user logs out.
+type systemdAutostarter struct {
+	options StartupOptions
+}
+
+// systemdUnitName derives the unit name; project names are already limited to
+// letters, digits, hyphens and underscores
+func systemdUnitName() string {
+	return strings.ToLower(startupAppName) + ".service"
+}
+
+// unitPath returns the user unit location, honoring XDG_CONFIG_HOME
+func (systemdAutostarter) unitPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(configHome) {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configHome, "systemd", "user", systemdUnitName()), nil
+}
+
+// unitFile renders the service unit for the given executable
+func (s systemdAutostarter) unitFile(exePath string) string {
+	target := s.options.Target
+	if target == "" {
+		target = "graphical-session.target"
+	}
+
+	command := []string{systemdExecArg(exePath)}
+	for _, arg := range launchArgs(s.options) {
+		command = append(command, systemdExecArg(arg))
+	}
+
+	var unit strings.Builder
+	unit.WriteString("[Unit]\n")
+	fmt.Fprintf(&unit, "Description=%s\n", startupAppName)
+	fmt.Fprintf(&unit, "After=%s\n", target)
+	fmt.Fprintf(&unit, "PartOf=%s\n", target)
+	// Give up after five crashes within a minute instead of restarting forever
+	unit.WriteString("StartLimitIntervalSec=60\n")
+	unit.WriteString("StartLimitBurst=5\n")
+	unit.WriteString("\n[Service]\n")
+	unit.WriteString("Type=simple\n")
+	if s.options.Delay > 0 {
+		fmt.Fprintf(&unit, "ExecStartPre=/bin/sleep %d\n", s.options.Delay)
+	}
+	fmt.Fprintf(&unit, "ExecStart=%s\n", strings.Join(command, " "))
+	unit.WriteString("Restart=on-failure\n")
+	unit.WriteString("RestartSec=5\n")
+	fmt.Fprintf(&unit, "SyslogIdentifier=%s\n", strings.TrimSuffix(systemdUnitName(), ".service"))
+	unit.WriteString("\n[Install]\n")
+	fmt.Fprintf(&unit, "WantedBy=%s\n", target)
+	return unit.String()
+}
+
+func (s systemdAutostarter) Enable() error {
+	unitPath, err := s.unitPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(unitPath), 0755); err != nil {
+		return err
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(unitPath, []byte(s.unitFile(exePath)), 0644); err != nil {
+		return err
+	}
+
+	if err := systemctlUser("daemon-reload"); err != nil {
+		return err
+	}
+	// Not --now: the running app is already the instance the user started
+	return systemctlUser("enable", systemdUnitName())
+}
+
+func (s systemdAutostarter) Disable() error {
+	unitPath, err := s.unitPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(unitPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := systemctlUser("disable", systemdUnitName()); err != nil {
+		return err
+	}
+	if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return systemctlUser("daemon-reload")
+}
+
+func (s systemdAutostarter) IsEnabled() (bool, error) {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return false, nil
+	}
+	// is-enabled exits non-zero for disabled or unknown units
+	err := exec.Command("systemctl", "--user", "is-enabled", "--quiet", systemdUnitName()).Run()
+	return err == nil, nil
+}
+
+// systemctlUser runs systemctl against the user's service manager
+func systemctlUser(args ...string) error {
+	out, err := exec.Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl --user %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// systemdExecArg quotes one ExecStart= argument; % and $ are expanded by systemd
+func systemdExecArg(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\;") {
+		arg = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(arg) + `"`
+	}
+	return strings.NewReplacer("%", "%%", "$", "$$").Replace(arg)
+}
+
+// desktopExecReserved are characters that force an Exec argument to be quoted
+const desktopExecReserved = " \t\n\"'\\><~|&;$*?#()`"
+
+// desktopExecArg quotes one Exec= argument as the Desktop Entry spec requires
+func desktopExecArg(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, desktopExecReserved) {
+		arg = `"` + strings.NewReplacer(`"`, `\"`, "`", "\\`", "$", `\$`, `\`, `\\`).Replace(arg) + `"`
+	}
+	// Exec is also a string value, so backslashes are escaped again; % starts a field code
+	return strings.NewReplacer(`\`, `\\`, "%", "%%").Replace(arg)
+}
+
+// desktopValue escapes a string value such as Name or Comment
+func desktopValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(value)
+}
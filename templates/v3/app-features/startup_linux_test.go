@@ -0,0 +1,101 @@
+//go:build linux
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestLinuxDesktopEntry(t *testing.T) {
+	cases := []struct {
+		name    string
+		exePath string
+		options StartupOptions
+		hidden  bool
+	}{
+		{name: "default", exePath: "/opt/app/bin/app"},
+		{name: "args_and_spaces", exePath: "/home/me/My Apps/app", options: StartupOptions{Args: []string{"--minimized", "--profile=work & play"}}},
+		{name: "delay", exePath: "/opt/app/bin/app", options: StartupOptions{Delay: 15}},
+		{name: "hidden", exePath: "/opt/app/bin/app", options: StartupOptions{HideWhenDisabled: true}, hidden: true},
+		{name: "special_chars", exePath: `/tmp/we"ird $dir\100%/app`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := xdgAutostarter{options: tc.options}.desktopEntry(tc.exePath, tc.hidden)
+			golden := filepath.Join("testdata", "startup", tc.name+".desktop")
+
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("missing golden file (run go test -update): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("desktop entry mismatch\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestLinuxSystemdUnit(t *testing.T) {
+	cases := []struct {
+		name    string
+		exePath string
+		options StartupOptions
+	}{
+		{name: "systemd_default", exePath: "/opt/app/bin/app"},
+		{name: "systemd_target_delay", exePath: "/home/me/My Apps/app", options: StartupOptions{Target: "default.target", Delay: 10, Args: []string{"--cache=$HOME/100%"}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := systemdAutostarter{options: tc.options}.unitFile(tc.exePath)
+			golden := filepath.Join("testdata", "startup", tc.name+".service")
+
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("missing golden file (run go test -update): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("systemd unit mismatch\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestLinuxDesktopPathHonorsXDGConfigHome(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	got, err := xdgAutostarter{}.desktopPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(configHome, "autostart", startupAppName+".desktop")
+	if got != want {
+		t.Errorf("desktopPath() = %q, want %q", got, want)
+	}
+}
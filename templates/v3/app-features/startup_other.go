@@ -0,0 +1,27 @@
+//go:build !windows && !darwin && !linux
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// unsupportedAutostarter reports that autostart is not available
+type unsupportedAutostarter struct{}
+
+func newAutostarter(options StartupOptions) Autostarter {
+	return unsupportedAutostarter{}
+}
+
+func (unsupportedAutostarter) Enable() error {
+	return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+}
+
+func (unsupportedAutostarter) Disable() error {
+	return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+}
+
+func (unsupportedAutostarter) IsEnabled() (bool, error) {
+	return false, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+}
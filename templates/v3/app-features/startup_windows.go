@@ -0,0 +1,68 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// runKeyPath is the per-user registry key for programs started at login
+const runKeyPath = `Software\Microsoft\Windows\CurrentVersion\Run`
+
+// registryAutostarter uses the HKCU Run key
+type registryAutostarter struct {
+	options StartupOptions
+}
+
+func newAutostarter(options StartupOptions) Autostarter {
+	return registryAutostarter{options: options}
+}
+
+func (r registryAutostarter) Enable() error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	command := []string{`"` + exePath + `"`}
+	for _, arg := range launchArgs(r.options) {
+		command = append(command, syscall.EscapeArg(arg))
+	}
+
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKeyPath, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	return key.SetStringValue(startupAppName, strings.Join(command, " "))
+}
+
+func (registryAutostarter) Disable() error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKeyPath, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	err = key.DeleteValue(startupAppName)
+	if err == registry.ErrNotExist {
+		return nil
+	}
+	return err
+}
+
+func (registryAutostarter) IsEnabled() (bool, error) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return false, nil
+	}
+	defer key.Close()
+
+	_, _, err = key.GetStringValue(startupAppName)
+	return err == nil, nil
+}
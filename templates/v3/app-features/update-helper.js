@@ -1,9 +1,9 @@
 // Auto-Update Helper
-import { CheckForUpdates, GetCurrentVersion, OpenReleaseURL } from '../wailsjs/go/main/App'
+import { UpdateService } from '../bindings/changeme'
 
 export async function checkForUpdates() {
   try {
-    const updateInfo = await CheckForUpdates()
+    const updateInfo = await UpdateService.CheckForUpdates()
     return updateInfo
   } catch (error) {
     console.error('Failed to check for updates:', error)
@@ -13,7 +13,7 @@ export async function checkForUpdates() {
 
 export async function getCurrentVersion() {
   try {
-    return await GetCurrentVersion()
+    return await UpdateService.GetCurrentVersion()
   } catch (error) {
     console.error('Failed to get current version:', error)
     return 'unknown'
@@ -22,7 +22,7 @@ export async function getCurrentVersion() {
 
 export async function openReleaseURL(url) {
   try {
-    await OpenReleaseURL(url)
+    await UpdateService.OpenReleaseURL(url)
   } catch (error) {
     console.error('Failed to open release URL:', error)
   }
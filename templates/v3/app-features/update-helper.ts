@@ -1,5 +1,5 @@
 // Auto-Update Helper
-import { CheckForUpdates, GetCurrentVersion, OpenReleaseURL } from '../wailsjs/go/main/App'
+import { UpdateService } from '../bindings/changeme'
 
 interface UpdateInfo {
   version: string
@@ -11,7 +11,7 @@ interface UpdateInfo {
 
 export async function checkForUpdates(): Promise<UpdateInfo | null> {
   try {
-    const updateInfo = await CheckForUpdates()
+    const updateInfo = await UpdateService.CheckForUpdates()
     return updateInfo
   } catch (error) {
     console.error('Failed to check for updates:', error)
@@ -21,7 +21,7 @@ export async function checkForUpdates(): Promise<UpdateInfo | null> {
 
 export async function getCurrentVersion(): Promise<string> {
   try {
-    return await GetCurrentVersion()
+    return await UpdateService.GetCurrentVersion()
   } catch (error) {
     console.error('Failed to get current version:', error)
     return 'unknown'
@@ -30,7 +30,7 @@ export async function getCurrentVersion(): Promise<string> {
 
 export async function openReleaseURL(url: string) {
   try {
-    await OpenReleaseURL(url)
+    await UpdateService.OpenReleaseURL(url)
   } catch (error) {
     console.error('Failed to open release URL:', error)
   }